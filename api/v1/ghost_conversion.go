@@ -0,0 +1,229 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	blogv2 "example.com/api/v2"
+)
+
+// V2GhostAnnotation keeps the v2 spec and status of a Ghost read through v1,
+// when they hold anything v1 cannot express. Converting the Ghost back up to
+// v2 starts from it, so nothing is lost on the round trip.
+const V2GhostAnnotation = "blog.example.com/v2-ghost"
+
+// v2Ghost is the content of the V2GhostAnnotation
+type v2Ghost struct {
+	Spec   blogv2.GhostSpec   `json:"spec,omitempty"`
+	Status blogv2.GhostStatus `json:"status,omitempty"`
+}
+
+// ConvertTo converts this Ghost (v1) to the Hub version (v2).
+func (src *Ghost) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*blogv2.Ghost)
+	if !ok {
+		return fmt.Errorf("expected a v2 Ghost but got %T", dstRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if data, ok := src.Annotations[V2GhostAnnotation]; ok {
+		restored := v2Ghost{}
+		if err := json.Unmarshal([]byte(data), &restored); err != nil {
+			return fmt.Errorf("decoding annotation %s: %w", V2GhostAnnotation, err)
+		}
+		dst.Spec, dst.Status = restored.Spec, restored.Status
+		delete(dst.Annotations, V2GhostAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	// Fields v1 knows about win over the ones restored from the annotation
+	convertSpecTo(&src.Spec, &dst.Spec)
+	convertStatusTo(&src.Status, &dst.Status)
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v2) to this version (v1).
+func (dst *Ghost) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*blogv2.Ghost)
+	if !ok {
+		return fmt.Errorf("expected a v2 Ghost but got %T", srcRaw)
+	}
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	convertSpecFrom(&src.Spec, &dst.Spec)
+	convertStatusFrom(&src.Status, &dst.Status)
+
+	// Keep the v2 fields that did not make it into v1
+	roundTrip := blogv2.GhostSpec{}
+	convertSpecTo(&dst.Spec, &roundTrip)
+	roundTripStatus := blogv2.GhostStatus{}
+	convertStatusTo(&dst.Status, &roundTripStatus)
+	if equality.Semantic.DeepEqual(roundTrip, src.Spec) && equality.Semantic.DeepEqual(roundTripStatus, src.Status) {
+		return nil
+	}
+	data, err := json.Marshal(v2Ghost{Spec: src.Spec, Status: src.Status})
+	if err != nil {
+		return err
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[V2GhostAnnotation] = string(data)
+	return nil
+}
+
+// convertSpecTo copies the fields of a v1 spec onto a v2 spec, leaving the
+// v2 only fields alone.
+func convertSpecTo(src *GhostSpec, dst *blogv2.GhostSpec) {
+	dst.Image.Tag = src.ImageTag
+
+	dst.Service.Type = src.Service.Type
+	dst.Service.Port = src.Service.Port
+	dst.Service.NodePort = src.Service.NodePort
+	dst.Service.Annotations = src.Service.Annotations
+
+	dst.Database.Client = blogv2.DatabaseClient(src.Database.Client)
+	dst.Database.Managed = src.Database.Managed
+	dst.Database.Host = src.Database.Host
+	dst.Database.Port = src.Database.Port
+	dst.Database.Name = src.Database.Name
+	dst.Database.User = src.Database.User
+	dst.Database.UserSecretRef = src.Database.UserSecretRef
+	dst.Database.PasswordSecretRef = src.Database.PasswordSecretRef
+
+	if src.Ingress == nil {
+		dst.Ingress = nil
+	} else {
+		if dst.Ingress == nil {
+			dst.Ingress = &blogv2.GhostIngressSpec{}
+		}
+		dst.Ingress.Hosts = src.Ingress.Hosts
+		dst.Ingress.ClassName = src.Ingress.ClassName
+		dst.Ingress.Path = src.Ingress.Path
+		dst.Ingress.TLSSecretName = src.Ingress.TLSSecretName
+		dst.Ingress.Issuer = src.Ingress.Issuer
+		dst.Ingress.Annotations = src.Ingress.Annotations
+	}
+
+	if src.HTTPRoute == nil {
+		dst.HTTPRoute = nil
+	} else {
+		if dst.HTTPRoute == nil {
+			dst.HTTPRoute = &blogv2.GhostHTTPRouteSpec{}
+		}
+		dst.HTTPRoute.ParentRefs = nil
+		for _, ref := range src.HTTPRoute.ParentRefs {
+			dst.HTTPRoute.ParentRefs = append(dst.HTTPRoute.ParentRefs, blogv2.GhostParentRef{
+				Name:        ref.Name,
+				Namespace:   ref.Namespace,
+				SectionName: ref.SectionName,
+			})
+		}
+		dst.HTTPRoute.Hostnames = src.HTTPRoute.Hostnames
+	}
+
+	dst.Storage.Size = src.Storage.Size
+	dst.Storage.StorageClassName = src.Storage.StorageClassName
+	dst.Storage.AccessModes = src.Storage.AccessModes
+	dst.Storage.ExistingClaim = src.Storage.ExistingClaim
+	dst.Storage.Selector = src.Storage.Selector
+	dst.Storage.RetentionPolicy = blogv2.RetentionPolicy(src.Storage.RetentionPolicy)
+	dst.Storage.VolumeSnapshotClassName = src.Storage.VolumeSnapshotClassName
+}
+
+// convertSpecFrom copies the fields of a v2 spec v1 can express onto a v1 spec
+func convertSpecFrom(src *blogv2.GhostSpec, dst *GhostSpec) {
+	dst.ImageTag = src.Image.Tag
+
+	dst.Service = GhostServiceSpec{
+		Type:        src.Service.Type,
+		Port:        src.Service.Port,
+		NodePort:    src.Service.NodePort,
+		Annotations: src.Service.Annotations,
+	}
+
+	dst.Database = GhostDatabaseSpec{
+		Client:            DatabaseClient(src.Database.Client),
+		Managed:           src.Database.Managed,
+		Host:              src.Database.Host,
+		Port:              src.Database.Port,
+		Name:              src.Database.Name,
+		User:              src.Database.User,
+		UserSecretRef:     src.Database.UserSecretRef,
+		PasswordSecretRef: src.Database.PasswordSecretRef,
+	}
+
+	dst.Ingress = nil
+	if src.Ingress != nil {
+		dst.Ingress = &GhostIngressSpec{
+			Hosts:         src.Ingress.Hosts,
+			ClassName:     src.Ingress.ClassName,
+			Path:          src.Ingress.Path,
+			TLSSecretName: src.Ingress.TLSSecretName,
+			Issuer:        src.Ingress.Issuer,
+			Annotations:   src.Ingress.Annotations,
+		}
+	}
+
+	dst.HTTPRoute = nil
+	if src.HTTPRoute != nil {
+		dst.HTTPRoute = &GhostHTTPRouteSpec{Hostnames: src.HTTPRoute.Hostnames}
+		for _, ref := range src.HTTPRoute.ParentRefs {
+			dst.HTTPRoute.ParentRefs = append(dst.HTTPRoute.ParentRefs, GhostParentRef{
+				Name:        ref.Name,
+				Namespace:   ref.Namespace,
+				SectionName: ref.SectionName,
+			})
+		}
+	}
+
+	dst.Storage = GhostStorageSpec{
+		Size:                    src.Storage.Size,
+		StorageClassName:        src.Storage.StorageClassName,
+		AccessModes:             src.Storage.AccessModes,
+		ExistingClaim:           src.Storage.ExistingClaim,
+		Selector:                src.Storage.Selector,
+		RetentionPolicy:         RetentionPolicy(src.Storage.RetentionPolicy),
+		VolumeSnapshotClassName: src.Storage.VolumeSnapshotClassName,
+	}
+}
+
+// convertStatusTo copies the fields of a v1 status onto a v2 status, leaving
+// the v2 only fields alone.
+func convertStatusTo(src *GhostStatus, dst *blogv2.GhostStatus) {
+	dst.Conditions = src.Conditions
+	dst.ObservedGeneration = src.ObservedGeneration
+	dst.DeploymentName = src.DeploymentName
+	dst.NodePort = src.NodePort
+}
+
+// convertStatusFrom copies the fields of a v2 status v1 can express onto a v1 status
+func convertStatusFrom(src *blogv2.GhostStatus, dst *GhostStatus) {
+	*dst = GhostStatus{
+		Conditions:         src.Conditions,
+		ObservedGeneration: src.ObservedGeneration,
+		DeploymentName:     src.DeploymentName,
+		NodePort:           src.NodePort,
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,17 +30,239 @@ type GhostSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	//+kubebuilder:validation:Pattern=`^[-a-z0-9]*$`
-	ImageTag string `json:"imageTag"`
+	// ImageTag of the ghost image to run, defaults to alpine
+	//+kubebuilder:validation:Pattern=`^([A-Za-z0-9_][A-Za-z0-9_.-]{0,127})?$`
+	// +optional
+	ImageTag string `json:"imageTag,omitempty"`
+
+	// Service configures how the blog is exposed inside and outside the cluster
+	// +optional
+	Service GhostServiceSpec `json:"service,omitempty"`
+
+	// Database configures the database Ghost stores its content in
+	// +optional
+	Database GhostDatabaseSpec `json:"database,omitempty"`
+
+	// Ingress exposes the blog on its hosts through a networking.k8s.io/v1 Ingress
+	// +optional
+	Ingress *GhostIngressSpec `json:"ingress,omitempty"`
+
+	// HTTPRoute exposes the blog on its hostnames through a Gateway API HTTPRoute
+	// +optional
+	HTTPRoute *GhostHTTPRouteSpec `json:"httpRoute,omitempty"`
+
+	// Storage configures the volume holding the Ghost content
+	// +optional
+	Storage GhostStorageSpec `json:"storage,omitempty"`
+}
+
+// GhostServiceSpec defines the Service in front of the Ghost pods
+type GhostServiceSpec struct {
+	// Type of the Service, defaults to NodePort
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Port exposed by the Service, defaults to 80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// NodePort to expose the Service on for NodePort and LoadBalancer types.
+	// The cluster allocates a free port when it is left empty.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// Annotations added to the Service, e.g. to configure a cloud load balancer
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// DatabaseClient is the database driver used by Ghost
+// +kubebuilder:validation:Enum=sqlite3;mysql
+type DatabaseClient string
+
+const (
+	// DatabaseClientSQLite stores the content in a SQLite file on the data volume
+	DatabaseClientSQLite DatabaseClient = "sqlite3"
+	// DatabaseClientMySQL stores the content in a MySQL database
+	DatabaseClientMySQL DatabaseClient = "mysql"
+)
+
+// GhostDatabaseSpec defines the database used by Ghost
+// +kubebuilder:validation:XValidation:rule="!has(self.client) || self.client != 'mysql' || (has(self.managed) && self.managed) || (has(self.host) && has(self.passwordSecretRef))",message="host and passwordSecretRef are required for an external mysql database"
+// +kubebuilder:validation:XValidation:rule="!has(self.managed) || !self.managed || !has(self.client) || self.client == 'mysql'",message="a managed database requires the mysql client"
+// +kubebuilder:validation:XValidation:rule="!has(self.user) || !has(self.userSecretRef)",message="user and userSecretRef are mutually exclusive"
+type GhostDatabaseSpec struct {
+	// Client is the database driver, defaults to sqlite3, or mysql when managed
+	// +optional
+	Client DatabaseClient `json:"client,omitempty"`
+
+	// Managed makes the operator run a MySQL StatefulSet for this Ghost. The
+	// connection settings below are ignored when it is set.
+	// +optional
+	Managed bool `json:"managed,omitempty"`
+
+	// Host of the MySQL server
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Port of the MySQL server, defaults to 3306
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Name of the MySQL database, defaults to ghost
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// User to connect to MySQL as, defaults to ghost
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// UserSecretRef selects the key of a Secret in the Ghost namespace holding
+	// the MySQL user, instead of user
+	// +optional
+	UserSecretRef *corev1.SecretKeySelector `json:"userSecretRef,omitempty"`
+
+	// PasswordSecretRef selects the key of a Secret in the Ghost namespace
+	// holding the MySQL password
+	// +optional
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+}
+
+// GhostIngressSpec defines the Ingress routing to the Ghost Service
+type GhostIngressSpec struct {
+	// Hosts the blog is served on. The first one is the primary host Ghost
+	// builds its url from.
+	// +kubebuilder:validation:MinItems=1
+	Hosts []string `json:"hosts"`
+
+	// ClassName of the Ingress controller to use
+	// +optional
+	ClassName *string `json:"className,omitempty"`
+
+	// Path the blog is served under, defaults to /
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// TLSSecretName is the Secret holding the certificate for the hosts.
+	// Defaults to ghost-tls-<name> when an issuer is set.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Issuer is the cert-manager ClusterIssuer to request the certificate from
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Annotations added to the Ingress
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GhostHTTPRouteSpec defines the Gateway API HTTPRoute routing to the Ghost Service
+type GhostHTTPRouteSpec struct {
+	// ParentRefs are the Gateways the route attaches to
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []GhostParentRef `json:"parentRefs"`
+
+	// Hostnames the blog is served on. The first one is the primary host Ghost
+	// builds its url from when no Ingress is configured.
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+}
+
+// GhostParentRef references a Gateway
+type GhostParentRef struct {
+	// Name of the Gateway
+	Name string `json:"name"`
+
+	// Namespace of the Gateway, defaults to the Ghost namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName selects a listener of the Gateway
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// RetentionPolicy decides what happens to the content of a Ghost when it is deleted
+type RetentionPolicy string
+
+const (
+	// RetentionPolicyDelete deletes the PVCs together with the Ghost
+	RetentionPolicyDelete RetentionPolicy = "Delete"
+	// RetentionPolicyRetain keeps the PVCs after the Ghost is gone
+	RetentionPolicyRetain RetentionPolicy = "Retain"
+	// RetentionPolicySnapshot takes a VolumeSnapshot of the PVCs before they are deleted
+	RetentionPolicySnapshot RetentionPolicy = "Snapshot"
+)
+
+// GhostStorageSpec defines the volumes of the Ghost
+// +kubebuilder:validation:XValidation:rule="!has(self.existingClaim) || !(has(self.size) || has(self.storageClassName) || has(self.accessModes) || has(self.selector))",message="existingClaim cannot be combined with size, storageClassName, accessModes or selector"
+type GhostStorageSpec struct {
+	// Size of the content volume, defaults to 1Gi. It can be grown when the
+	// storage class allows volume expansion, but never shrunk.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// StorageClassName of the content volume, the cluster default is used when it is left empty
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// AccessModes of the content volume, defaults to ReadWriteOnce
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// ExistingClaim is the name of a PVC to store the content in instead of
+	// one created for the Ghost. The operator neither resizes nor deletes it.
+	// +optional
+	ExistingClaim string `json:"existingClaim,omitempty"`
+
+	// Selector restricts the PersistentVolumes the content volume can bind to
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// RetentionPolicy for the content and managed database PVCs when the
+	// Ghost is deleted, defaults to Delete
+	// +kubebuilder:validation:Enum=Delete;Retain;Snapshot
+	// +optional
+	RetentionPolicy RetentionPolicy `json:"retentionPolicy,omitempty"`
+
+	// VolumeSnapshotClassName used for the Snapshot retention policy. The
+	// default class of the CSI driver is used when it is left empty.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
 }
 
 // GhostStatus defines the observed state of Ghost
 type GhostStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the generation of the Ghost the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// DeploymentName is the name of the Deployment running the Ghost pods
+	// +optional
+	DeploymentName string `json:"deploymentName,omitempty"`
+
+	// NodePort the Service is exposed on, if any
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="Progressing",type=string,JSONPath=`.status.conditions[?(@.type=="Progressing")].status`
+// +kubebuilder:printcolumn:name="Deployment",type=string,JSONPath=`.status.deploymentName`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Ghost is the Schema for the ghosts API
 type Ghost struct {
@@ -0,0 +1,120 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Defaults of the fields a Ghost may leave empty. The defaulting webhook
+// writes them into the spec, and the controller falls back to them for
+// Ghosts stored before the webhook was enabled.
+const (
+	DefaultImageRepository   = "ghost"
+	DefaultImageTag          = "alpine"
+	DefaultReplicas          = int32(1)
+	DefaultServiceType       = corev1.ServiceTypeNodePort
+	DefaultServicePort       = int32(80)
+	DefaultStorageSize       = "1Gi"
+	DefaultStorageAccessMode = corev1.ReadWriteOnce
+	DefaultRetentionPolicy   = RetentionPolicyDelete
+)
+
+// ImageReference returns the image reference to run, ghost:alpine by default.
+// A digest is appended to the tag, so the pulled image is pinned.
+func (s *GhostSpec) ImageReference() string {
+	repository, tag := s.Image.Repository, s.Image.Tag
+	if repository == "" {
+		repository = DefaultImageRepository
+	}
+	if tag == "" && s.Image.Digest == "" {
+		tag = DefaultImageTag
+	}
+
+	ref := repository
+	if tag != "" {
+		ref += ":" + tag
+	}
+	if s.Image.Digest != "" {
+		ref += "@" + s.Image.Digest
+	}
+	return ref
+}
+
+// DatabaseClient returns the database driver used by Ghost, sqlite3 by
+// default and mysql for a managed database.
+func (s *GhostSpec) DatabaseClient() DatabaseClient {
+	if s.Database.Managed {
+		return DatabaseClientMySQL
+	}
+	if s.Database.Client == "" {
+		return DatabaseClientSQLite
+	}
+	return s.Database.Client
+}
+
+// ServiceType returns the type of the Service, NodePort by default.
+func (s *GhostSpec) ServiceType() corev1.ServiceType {
+	if s.Service.Type == "" {
+		return DefaultServiceType
+	}
+	return s.Service.Type
+}
+
+// ServicePort returns the port of the Service, 80 by default.
+func (s *GhostSpec) ServicePort() int32 {
+	if s.Service.Port == 0 {
+		return DefaultServicePort
+	}
+	return s.Service.Port
+}
+
+// ServiceNodePort returns the explicit node port of the Service. Zero lets
+// the cluster allocate one, and is always used for ClusterIP Services.
+func (s *GhostSpec) ServiceNodePort() int32 {
+	if s.ServiceType() == corev1.ServiceTypeClusterIP {
+		return 0
+	}
+	return s.Service.NodePort
+}
+
+// StorageSize returns the size of the content volume, 1Gi by default.
+func (s *GhostSpec) StorageSize() resource.Quantity {
+	if s.Storage.Size == nil {
+		return resource.MustParse(DefaultStorageSize)
+	}
+	return s.Storage.Size.DeepCopy()
+}
+
+// StorageAccessModes returns the access modes of the content volume,
+// ReadWriteOnce by default.
+func (s *GhostSpec) StorageAccessModes() []corev1.PersistentVolumeAccessMode {
+	if len(s.Storage.AccessModes) == 0 {
+		return []corev1.PersistentVolumeAccessMode{DefaultStorageAccessMode}
+	}
+	return s.Storage.AccessModes
+}
+
+// RetentionPolicy returns what happens to the data of the Ghost once it is
+// deleted, Delete by default.
+func (s *GhostSpec) RetentionPolicy() RetentionPolicy {
+	if s.Storage.RetentionPolicy == "" {
+		return DefaultRetentionPolicy
+	}
+	return s.Storage.RetentionPolicy
+}
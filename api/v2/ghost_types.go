@@ -0,0 +1,808 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// GhostSpec defines the desired state of Ghost
+type GhostSpec struct {
+	// Replicas of Ghost to run, defaults to 1. More than one replica requires
+	// a MySQL database and content storage shared by every pod.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Autoscaling scales the Ghost pods with a HorizontalPodAutoscaler. It
+	// takes over from replicas when it is set.
+	// +optional
+	Autoscaling *GhostAutoscalingSpec `json:"autoscaling,omitempty"`
+
+	// Image of Ghost to run
+	// +optional
+	Image GhostImageSpec `json:"image,omitempty"`
+
+	// Storage configures the volume holding the Ghost content
+	// +optional
+	Storage GhostStorageSpec `json:"storage,omitempty"`
+
+	// Database configures the database Ghost stores its content in
+	// +optional
+	Database GhostDatabaseSpec `json:"database,omitempty"`
+
+	// Service configures how the blog is exposed inside and outside the cluster
+	// +optional
+	Service GhostServiceSpec `json:"service,omitempty"`
+
+	// Ingress exposes the blog on its hosts through a networking.k8s.io/v1 Ingress
+	// +optional
+	Ingress *GhostIngressSpec `json:"ingress,omitempty"`
+
+	// HTTPRoute exposes the blog on its hostnames through a Gateway API HTTPRoute
+	// +optional
+	HTTPRoute *GhostHTTPRouteSpec `json:"httpRoute,omitempty"`
+
+	// Resources of the Ghost container
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Config is the Ghost site configuration
+	// +optional
+	Config GhostConfigSpec `json:"config,omitempty"`
+
+	// Environment Ghost runs in, it sets NODE_ENV. Defaults to development.
+	// Production requires a url and a MySQL database, and applies resource
+	// requests to the container.
+	// +optional
+	Environment Environment `json:"environment,omitempty"`
+
+	// Probes tunes the health checks of the Ghost container
+	// +optional
+	Probes GhostProbesSpec `json:"probes,omitempty"`
+
+	// NodeSelector restricts the nodes the Ghost pods are scheduled on
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations of the Ghost pods
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Affinity of the Ghost pods
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpreadConstraints of the Ghost pods. A constraint without a
+	// labelSelector selects the pods of this Ghost.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName of the Ghost pods
+	// +optional
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// PodSecurityContext of the Ghost pods. Defaults to running as the node
+	// user of the Ghost image, owning the content volume.
+	// +optional
+	PodSecurityContext *corev1.PodSecurityContext `json:"podSecurityContext,omitempty"`
+
+	// SecurityContext of the Ghost container. Defaults to a read-only root
+	// filesystem without privilege escalation or capabilities.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+
+	// Backup takes GhostBackups of the Ghost on a schedule
+	// +optional
+	Backup *GhostBackupScheduleSpec `json:"backup,omitempty"`
+
+	// Upgrade configures how a change of the image is rolled out. The
+	// database is backed up and migrated before the new image serves, and
+	// restored along with the previous image when it never becomes available.
+	// +optional
+	Upgrade *GhostUpgradeSpec `json:"upgrade,omitempty"`
+}
+
+// GhostUpgradeSpec defines how the Ghost moves to a new image
+type GhostUpgradeSpec struct {
+	// BackupTarget stores the backup taken before the upgrade. Defaults to the
+	// target of the scheduled backups. Without a target the upgrade is blocked,
+	// unless skipBackup is set.
+	// +optional
+	BackupTarget *BackupTarget `json:"backupTarget,omitempty"`
+
+	// SkipBackup upgrades without a backup when no target is set. A rollback
+	// then only reverts the image, on a database the migrations may have
+	// changed already.
+	// +optional
+	SkipBackup bool `json:"skipBackup,omitempty"`
+
+	// ProgressDeadline is how long the new pods have to become available
+	// before the upgrade is rolled back, defaults to 10m
+	// +optional
+	ProgressDeadline *metav1.Duration `json:"progressDeadline,omitempty"`
+}
+
+// GhostBackupScheduleSpec defines the scheduled backups of a Ghost
+type GhostBackupScheduleSpec struct {
+	// Schedule of the backups in cron format, e.g. "0 3 * * *". A
+	// CRON_TZ=<zone> prefix sets its time zone, UTC by default.
+	// +kubebuilder:validation:MinLength=1
+	Schedule string `json:"schedule"`
+
+	// Suspend stops taking backups without deleting the existing ones
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Target the backup archives are stored in
+	Target BackupTarget `json:"target"`
+
+	// Retention decides which scheduled backups are kept. Expired backups are
+	// deleted along with their archive.
+	// +optional
+	Retention BackupRetention `json:"retention,omitempty"`
+}
+
+// BackupRetention defines which successful scheduled backups are kept. A
+// backup is kept when any rule selects it. Without any rule the last 7 are kept.
+type BackupRetention struct {
+	// KeepLast keeps the most recent backups
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepLast *int32 `json:"keepLast,omitempty"`
+
+	// KeepDaily keeps the most recent backup of as many days
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepDaily *int32 `json:"keepDaily,omitempty"`
+
+	// KeepWeekly keeps the most recent backup of as many weeks
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepWeekly *int32 `json:"keepWeekly,omitempty"`
+
+	// KeepMonthly keeps the most recent backup of as many months
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	KeepMonthly *int32 `json:"keepMonthly,omitempty"`
+}
+
+// GhostAutoscalingSpec defines the HorizontalPodAutoscaler of the Ghost pods
+// +kubebuilder:validation:XValidation:rule="!has(self.minReplicas) || self.minReplicas <= self.maxReplicas",message="minReplicas cannot be greater than maxReplicas"
+type GhostAutoscalingSpec struct {
+	// MinReplicas the autoscaler scales down to, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas the autoscaler scales up to
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilizationPercentage of the CPU requests. Defaults to 80 when
+	// no target is set.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetCPUUtilizationPercentage *int32 `json:"targetCPUUtilizationPercentage,omitempty"`
+
+	// TargetMemoryUtilizationPercentage of the memory requests
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TargetMemoryUtilizationPercentage *int32 `json:"targetMemoryUtilizationPercentage,omitempty"`
+}
+
+// GhostProbesSpec defines the health checks of the Ghost container. They
+// request the site on port 2368 with the Host header of the url.
+type GhostProbesSpec struct {
+	// Startup holds the other probes back until Ghost answers. It defaults
+	// to 10 minutes, enough for the migrations of a first boot.
+	// +optional
+	Startup *GhostProbeSpec `json:"startup,omitempty"`
+
+	// Liveness restarts a Ghost that stopped answering
+	// +optional
+	Liveness *GhostProbeSpec `json:"liveness,omitempty"`
+
+	// Readiness takes a Ghost that does not answer out of the Service
+	// +optional
+	Readiness *GhostProbeSpec `json:"readiness,omitempty"`
+}
+
+// GhostProbeSpec defines the thresholds of a probe. Unset fields keep the
+// defaults of the probe.
+type GhostProbeSpec struct {
+	// Disabled removes the probe
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// InitialDelaySeconds after the container started before probing
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// PeriodSeconds between two probes
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// TimeoutSeconds before a probe fails
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// FailureThreshold is the number of failed probes in a row before giving up
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// Environment is the mode Ghost runs in
+// +kubebuilder:validation:Enum=development;production
+type Environment string
+
+const (
+	// EnvironmentDevelopment runs Ghost as it is started by default
+	EnvironmentDevelopment Environment = "development"
+	// EnvironmentProduction runs Ghost with its production settings
+	EnvironmentProduction Environment = "production"
+)
+
+// GhostImageSpec defines the container image of Ghost
+type GhostImageSpec struct {
+	// Repository of the image including its registry, defaults to ghost on Docker Hub
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^([a-zA-Z0-9.-]+(:[0-9]+)?/)?[a-z0-9]+(([._]|__|-+)[a-z0-9]+)*(/[a-z0-9]+(([._]|__|-+)[a-z0-9]+)*)*$`
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Tag of the image, defaults to alpine
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`
+	// +optional
+	Tag string `json:"tag,omitempty"`
+
+	// Digest pins the image, e.g. sha256:<hex>. It takes precedence over the
+	// tag when pulling.
+	// +kubebuilder:validation:Pattern=`^sha256:[a-f0-9]{64}$`
+	// +optional
+	Digest string `json:"digest,omitempty"`
+
+	// PullPolicy of the image, defaults to the Kubernetes default for the reference
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	// +optional
+	PullPolicy corev1.PullPolicy `json:"pullPolicy,omitempty"`
+
+	// ImagePullSecrets are the Secrets in the Ghost namespace used to pull the image
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+}
+
+// GhostConfigSpec defines the site configuration of Ghost. It is rendered into
+// the __ delimited environment variables Ghost reads its config from.
+type GhostConfigSpec struct {
+	// URL the blog is published on. Defaults to the first host of the Ingress
+	// or HTTPRoute.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// Admin configures the admin client
+	// +optional
+	Admin *GhostAdminSpec `json:"admin,omitempty"`
+
+	// Mail configures how Ghost sends transactional emails
+	// +optional
+	Mail *GhostMailSpec `json:"mail,omitempty"`
+
+	// Privacy toggles the features of Ghost that contact third party services
+	// +optional
+	Privacy *GhostPrivacySpec `json:"privacy,omitempty"`
+
+	// Logging configures the log output of Ghost
+	// +optional
+	Logging *GhostLoggingSpec `json:"logging,omitempty"`
+
+	// ExtraEnv adds environment variables to the Ghost container, for settings
+	// without a typed field. They take precedence over the rendered ones.
+	// +optional
+	ExtraEnv []corev1.EnvVar `json:"extraEnv,omitempty"`
+
+	// EnvFrom adds environment variables from ConfigMaps or Secrets to the Ghost container
+	// +optional
+	EnvFrom []corev1.EnvFromSource `json:"envFrom,omitempty"`
+}
+
+// GhostAdminSpec defines the admin client of Ghost
+type GhostAdminSpec struct {
+	// URL the admin client is served on, when it differs from the site url
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	URL string `json:"url,omitempty"`
+}
+
+// MailTransport is the way Ghost delivers emails
+type MailTransport string
+
+const (
+	// MailTransportSMTP sends emails through an SMTP server
+	MailTransportSMTP MailTransport = "SMTP"
+	// MailTransportDirect lets Ghost deliver emails to the recipient servers itself
+	MailTransportDirect MailTransport = "Direct"
+)
+
+// GhostMailSpec defines the mail transport of Ghost
+// +kubebuilder:validation:XValidation:rule="has(self.transport) && self.transport == 'Direct' || has(self.smtp)",message="smtp is required for the SMTP transport"
+type GhostMailSpec struct {
+	// Transport used to send emails, defaults to SMTP
+	// +kubebuilder:validation:Enum=SMTP;Direct
+	// +optional
+	Transport MailTransport `json:"transport,omitempty"`
+
+	// From is the address emails are sent from
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// SMTP server to send emails through
+	// +optional
+	SMTP *GhostSMTPSpec `json:"smtp,omitempty"`
+}
+
+// GhostSMTPSpec defines the SMTP server Ghost sends emails through
+type GhostSMTPSpec struct {
+	// Host of the SMTP server
+	Host string `json:"host"`
+
+	// Port of the SMTP server, defaults to 587, or 465 when secure
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Secure connects with TLS from the start instead of upgrading with STARTTLS
+	// +optional
+	Secure bool `json:"secure,omitempty"`
+
+	// UserSecretRef selects the key of a Secret in the Ghost namespace holding
+	// the SMTP user name
+	// +optional
+	UserSecretRef *corev1.SecretKeySelector `json:"userSecretRef,omitempty"`
+
+	// PasswordSecretRef selects the key of a Secret in the Ghost namespace
+	// holding the SMTP password
+	// +optional
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+}
+
+// GhostPrivacySpec defines the privacy settings of Ghost. Unset toggles keep
+// the Ghost default.
+type GhostPrivacySpec struct {
+	// UseTinfoil disables every feature below at once
+	// +optional
+	UseTinfoil *bool `json:"useTinfoil,omitempty"`
+
+	// UseUpdateCheck checks for new Ghost releases
+	// +optional
+	UseUpdateCheck *bool `json:"useUpdateCheck,omitempty"`
+
+	// UseGravatar loads author avatars from Gravatar
+	// +optional
+	UseGravatar *bool `json:"useGravatar,omitempty"`
+
+	// UseRpcPing pings search engines when a post is published
+	// +optional
+	UseRpcPing *bool `json:"useRpcPing,omitempty"`
+
+	// UseStructuredData adds structured data to the pages
+	// +optional
+	UseStructuredData *bool `json:"useStructuredData,omitempty"`
+}
+
+// GhostLoggingSpec defines the logging of Ghost
+type GhostLoggingSpec struct {
+	// Level of the log output
+	// +kubebuilder:validation:Enum=error;warn;info;debug
+	// +optional
+	Level string `json:"level,omitempty"`
+
+	// Transports the logs are written to, defaults to stdout
+	// +optional
+	Transports []GhostLoggingTransport `json:"transports,omitempty"`
+}
+
+// GhostLoggingTransport is a log output of Ghost
+// +kubebuilder:validation:Enum=stdout;file
+type GhostLoggingTransport string
+
+// GhostServiceSpec defines the Service in front of the Ghost pods
+type GhostServiceSpec struct {
+	// Type of the Service, defaults to NodePort
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	// +optional
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Port exposed by the Service, defaults to 80
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// NodePort to expose the Service on for NodePort and LoadBalancer types.
+	// The cluster allocates a free port when it is left empty.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// Annotations added to the Service, e.g. to configure a cloud load balancer
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// DatabaseClient is the database driver used by Ghost
+// +kubebuilder:validation:Enum=sqlite3;mysql
+type DatabaseClient string
+
+const (
+	// DatabaseClientSQLite stores the content in a SQLite file on the data volume
+	DatabaseClientSQLite DatabaseClient = "sqlite3"
+	// DatabaseClientMySQL stores the content in a MySQL database
+	DatabaseClientMySQL DatabaseClient = "mysql"
+)
+
+// GhostDatabaseSpec defines the database used by Ghost
+// +kubebuilder:validation:XValidation:rule="!has(self.client) || self.client != 'mysql' || (has(self.managed) && self.managed) || (has(self.host) && has(self.passwordSecretRef))",message="host and passwordSecretRef are required for an external mysql database"
+// +kubebuilder:validation:XValidation:rule="!has(self.managed) || !self.managed || !has(self.client) || self.client == 'mysql'",message="a managed database requires the mysql client"
+// +kubebuilder:validation:XValidation:rule="!has(self.user) || !has(self.userSecretRef)",message="user and userSecretRef are mutually exclusive"
+// +kubebuilder:validation:XValidation:rule="!has(self.sqlite) || ((!has(self.managed) || !self.managed) && (!has(self.client) || self.client == 'sqlite3'))",message="sqlite settings require the sqlite3 client"
+type GhostDatabaseSpec struct {
+	// Client is the database driver, defaults to sqlite3, or mysql when managed
+	// +optional
+	Client DatabaseClient `json:"client,omitempty"`
+
+	// Managed makes the operator run a MySQL StatefulSet for this Ghost. The
+	// connection settings below are ignored when it is set.
+	// +optional
+	Managed bool `json:"managed,omitempty"`
+
+	// Host of the MySQL server
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Port of the MySQL server, defaults to 3306
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port int32 `json:"port,omitempty"`
+
+	// Name of the MySQL database, defaults to ghost
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// User to connect to MySQL as, defaults to ghost
+	// +optional
+	User string `json:"user,omitempty"`
+
+	// UserSecretRef selects the key of a Secret in the Ghost namespace holding
+	// the MySQL user, instead of user
+	// +optional
+	UserSecretRef *corev1.SecretKeySelector `json:"userSecretRef,omitempty"`
+
+	// PasswordSecretRef selects the key of a Secret in the Ghost namespace
+	// holding the MySQL password
+	// +optional
+	PasswordSecretRef *corev1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+
+	// AllowSQLiteInProduction runs a production Ghost on SQLite anyway. SQLite
+	// is not supported by Ghost in production.
+	// +optional
+	AllowSQLiteInProduction bool `json:"allowSQLiteInProduction,omitempty"`
+
+	// SQLite configures the sqlite3 database
+	// +optional
+	SQLite *GhostSQLiteSpec `json:"sqlite,omitempty"`
+}
+
+// GhostSQLiteSpec defines the settings of the sqlite3 database
+type GhostSQLiteSpec struct {
+	// Replication streams the database to an object storage as it changes,
+	// for point-in-time recovery
+	// +optional
+	Replication *SQLiteReplicationSpec `json:"replication,omitempty"`
+}
+
+// SQLiteReplicationSpec defines the Litestream replica of the sqlite3
+// database. The database is restored from the replica when the content
+// volume holds none, on a fresh PVC for instance.
+type SQLiteReplicationSpec struct {
+	// S3 is the bucket the database is streamed to. The replica is stored
+	// under <prefix>/<namespace>/<ghost name>.
+	S3 S3BackupTarget `json:"s3"`
+
+	// SyncInterval is how often changes are shipped to the bucket, defaults to 1s
+	// +optional
+	SyncInterval *metav1.Duration `json:"syncInterval,omitempty"`
+
+	// Retention is how long the changes are kept, bounding how far back the
+	// database can be recovered. Defaults to 24h.
+	// +optional
+	Retention *metav1.Duration `json:"retention,omitempty"`
+}
+
+// GhostIngressSpec defines the Ingress routing to the Ghost Service
+type GhostIngressSpec struct {
+	// Hosts the blog is served on. The first one is the primary host Ghost
+	// builds its url from.
+	// +kubebuilder:validation:MinItems=1
+	Hosts []string `json:"hosts"`
+
+	// ClassName of the Ingress controller to use
+	// +optional
+	ClassName *string `json:"className,omitempty"`
+
+	// Path the blog is served under, defaults to /
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// TLSSecretName is the Secret holding the certificate for the hosts.
+	// Defaults to ghost-tls-<name> when an issuer is set.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Issuer is the cert-manager ClusterIssuer to request the certificate from
+	// +optional
+	Issuer string `json:"issuer,omitempty"`
+
+	// Annotations added to the Ingress
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GhostHTTPRouteSpec defines the Gateway API HTTPRoute routing to the Ghost Service
+type GhostHTTPRouteSpec struct {
+	// ParentRefs are the Gateways the route attaches to
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []GhostParentRef `json:"parentRefs"`
+
+	// Hostnames the blog is served on. The first one is the primary host Ghost
+	// builds its url from when no Ingress is configured.
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+}
+
+// GhostParentRef references a Gateway
+type GhostParentRef struct {
+	// Name of the Gateway
+	Name string `json:"name"`
+
+	// Namespace of the Gateway, defaults to the Ghost namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName selects a listener of the Gateway
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// RetentionPolicy decides what happens to the content of a Ghost when it is deleted
+type RetentionPolicy string
+
+const (
+	// RetentionPolicyDelete deletes the PVCs together with the Ghost
+	RetentionPolicyDelete RetentionPolicy = "Delete"
+	// RetentionPolicyRetain keeps the PVCs after the Ghost is gone
+	RetentionPolicyRetain RetentionPolicy = "Retain"
+	// RetentionPolicySnapshot takes a VolumeSnapshot of the PVCs before they are deleted
+	RetentionPolicySnapshot RetentionPolicy = "Snapshot"
+)
+
+// GhostStorageSpec defines the volumes of the Ghost
+// +kubebuilder:validation:XValidation:rule="!has(self.existingClaim) || !(has(self.size) || has(self.storageClassName) || has(self.accessModes) || has(self.selector))",message="existingClaim cannot be combined with size, storageClassName, accessModes or selector"
+type GhostStorageSpec struct {
+	// Size of the content volume, defaults to 1Gi. It can be grown when the
+	// storage class allows volume expansion, but never shrunk.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+
+	// StorageClassName of the content volume, the cluster default is used when it is left empty
+	// +optional
+	StorageClassName *string `json:"storageClassName,omitempty"`
+
+	// AccessModes of the content volume, defaults to ReadWriteOnce
+	// +optional
+	AccessModes []corev1.PersistentVolumeAccessMode `json:"accessModes,omitempty"`
+
+	// ExistingClaim is the name of a PVC to store the content in instead of
+	// one created for the Ghost. The operator neither resizes nor deletes it.
+	// +optional
+	ExistingClaim string `json:"existingClaim,omitempty"`
+
+	// Selector restricts the PersistentVolumes the content volume can bind to
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// RetentionPolicy for the content and managed database PVCs when the
+	// Ghost is deleted, defaults to Delete
+	// +kubebuilder:validation:Enum=Delete;Retain;Snapshot
+	// +optional
+	RetentionPolicy RetentionPolicy `json:"retentionPolicy,omitempty"`
+
+	// VolumeSnapshotClassName used for the Snapshot retention policy. The
+	// default class of the CSI driver is used when it is left empty.
+	// +optional
+	VolumeSnapshotClassName *string `json:"volumeSnapshotClassName,omitempty"`
+
+	// Adapter is the Ghost storage adapter images are uploaded to instead of
+	// the content volume, e.g. s3. The adapter has to be installed in the
+	// image and is configured through spec.config.extraEnv.
+	// +optional
+	Adapter string `json:"adapter,omitempty"`
+}
+
+// GhostStatus defines the observed state of Ghost
+type GhostStatus struct {
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// ObservedGeneration is the generation of the Ghost the status was computed for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// DeploymentName is the name of the Deployment running the Ghost pods
+	// +optional
+	DeploymentName string `json:"deploymentName,omitempty"`
+
+	// NodePort the Service is exposed on, if any
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// Image the Ghost pods run. It carries the digest of the pulled image once
+	// the pods are running.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Replicas of Ghost running, as reported by the Deployment
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// Selector of the Ghost pods in label selector string form, for the
+	// scale subresource
+	// +optional
+	Selector string `json:"selector,omitempty"`
+
+	// LastSuccessfulBackup is when the last GhostBackup of the Ghost that
+	// succeeded was taken
+	// +optional
+	LastSuccessfulBackup *metav1.Time `json:"lastSuccessfulBackup,omitempty"`
+
+	// NextScheduledBackup is when the schedule takes the next GhostBackup
+	// +optional
+	NextScheduledBackup *metav1.Time `json:"nextScheduledBackup,omitempty"`
+
+	// Upgrade is the last change of the image, in progress or finished
+	// +optional
+	Upgrade *GhostUpgradeStatus `json:"upgrade,omitempty"`
+}
+
+// GhostUpgradePhase is the stage an upgrade is in
+type GhostUpgradePhase string
+
+const (
+	// GhostUpgradePhaseBlocked upgrades wait for a backup target, the Ghost
+	// keeps the previous image meanwhile
+	GhostUpgradePhaseBlocked GhostUpgradePhase = "Blocked"
+	// GhostUpgradePhaseBackingUp upgrades wait for the pre-upgrade GhostBackup
+	GhostUpgradePhaseBackingUp GhostUpgradePhase = "BackingUp"
+	// GhostUpgradePhaseMigrating upgrades have the Ghost scaled down and a Job
+	// migrating the database with the new image
+	GhostUpgradePhaseMigrating GhostUpgradePhase = "Migrating"
+	// GhostUpgradePhaseRollingOut upgrades wait for the new pods to be available
+	GhostUpgradePhaseRollingOut GhostUpgradePhase = "RollingOut"
+	// GhostUpgradePhaseRollingBack upgrades restore the pre-upgrade backup
+	// with the previous image
+	GhostUpgradePhaseRollingBack GhostUpgradePhase = "RollingBack"
+	// GhostUpgradePhaseSucceeded upgrades have the Ghost running the new image
+	GhostUpgradePhaseSucceeded GhostUpgradePhase = "Succeeded"
+	// GhostUpgradePhaseRolledBack upgrades have the Ghost running the previous image again
+	GhostUpgradePhaseRolledBack GhostUpgradePhase = "RolledBack"
+	// GhostUpgradePhaseFailed upgrades could neither be completed nor rolled back
+	GhostUpgradePhaseFailed GhostUpgradePhase = "Failed"
+)
+
+// GhostUpgradeStatus defines the observed state of an upgrade. A rolled back
+// or failed upgrade keeps the previous image until the Ghost asks for another one.
+type GhostUpgradeStatus struct {
+	// Phase of the upgrade
+	// +optional
+	Phase GhostUpgradePhase `json:"phase,omitempty"`
+
+	// Message explains the phase
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// FromImage is the image the Ghost ran before the upgrade
+	// +optional
+	FromImage string `json:"fromImage,omitempty"`
+
+	// ToImage is the image the Ghost is upgraded to
+	// +optional
+	ToImage string `json:"toImage,omitempty"`
+
+	// ObservedGeneration is the generation of the Ghost the upgrade started for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// BackupName is the GhostBackup taken before the upgrade
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// MigrationJobName is the Job migrating the database with the new image
+	// +optional
+	MigrationJobName string `json:"migrationJobName,omitempty"`
+
+	// RestoreName is the GhostRestore rolling the database back
+	// +optional
+	RestoreName string `json:"restoreName,omitempty"`
+
+	// StartTime is when the upgrade was detected
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// RolloutStartTime is when the new image was rolled out
+	// +optional
+	RolloutStartTime *metav1.Time `json:"rolloutStartTime,omitempty"`
+
+	// CompletionTime is when the upgrade succeeded, was rolled back or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.replicas,statuspath=.status.replicas,selectorpath=.status.selector
+// +kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.status.replicas`
+// +kubebuilder:printcolumn:name="Available",type=string,JSONPath=`.status.conditions[?(@.type=="Available")].status`
+// +kubebuilder:printcolumn:name="Progressing",type=string,JSONPath=`.status.conditions[?(@.type=="Progressing")].status`
+// +kubebuilder:printcolumn:name="Image",type=string,JSONPath=`.status.image`,priority=1
+// +kubebuilder:printcolumn:name="Deployment",type=string,JSONPath=`.status.deploymentName`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// Ghost is the Schema for the ghosts API
+type Ghost struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GhostSpec   `json:"spec,omitempty"`
+	Status GhostStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GhostList contains a list of Ghost
+type GhostList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Ghost `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Ghost{}, &GhostList{})
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GhostBackupSpec defines the desired state of GhostBackup
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type GhostBackupSpec struct {
+	// GhostName is the Ghost in the namespace of the backup to back up
+	// +kubebuilder:validation:MinLength=1
+	GhostName string `json:"ghostName"`
+
+	// Target the backup archive is stored in
+	Target BackupTarget `json:"target"`
+}
+
+// BackupTarget defines where backup archives are stored. Exactly one of s3
+// and pvc has to be set.
+// +kubebuilder:validation:XValidation:rule="has(self.s3) != has(self.pvc)",message="exactly one of s3 and pvc is required"
+type BackupTarget struct {
+	// S3 stores the archives in a bucket of an S3 compatible object storage
+	// +optional
+	S3 *S3BackupTarget `json:"s3,omitempty"`
+
+	// PVC stores the archives on a PersistentVolumeClaim
+	// +optional
+	PVC *PVCBackupTarget `json:"pvc,omitempty"`
+}
+
+// S3BackupTarget defines a bucket of an S3 compatible object storage
+type S3BackupTarget struct {
+	// Bucket the archives are uploaded to
+	// +kubebuilder:validation:MinLength=1
+	Bucket string `json:"bucket"`
+
+	// Prefix of the archive keys in the bucket
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Endpoint of the object storage, e.g. http://minio.minio.svc:9000.
+	// AWS S3 is used when it is left empty.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Region of the bucket
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecretRef is a Secret in the namespace of the backup holding
+	// the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY of the bucket
+	CredentialsSecretRef corev1.LocalObjectReference `json:"credentialsSecretRef"`
+}
+
+// PVCBackupTarget defines a PersistentVolumeClaim archives are stored on
+type PVCBackupTarget struct {
+	// ClaimName of the PVC in the namespace of the backup
+	// +kubebuilder:validation:MinLength=1
+	ClaimName string `json:"claimName"`
+
+	// Path of the directory on the volume the archives are stored in
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// GhostBackupPhase is the stage a backup is in
+type GhostBackupPhase string
+
+const (
+	// GhostBackupPhasePending backups wait for their Job to be created
+	GhostBackupPhasePending GhostBackupPhase = "Pending"
+	// GhostBackupPhaseRunning backups have a Job archiving the Ghost
+	GhostBackupPhaseRunning GhostBackupPhase = "Running"
+	// GhostBackupPhaseSucceeded backups have their archive stored in the target
+	GhostBackupPhaseSucceeded GhostBackupPhase = "Succeeded"
+	// GhostBackupPhaseFailed backups could not be taken
+	GhostBackupPhaseFailed GhostBackupPhase = "Failed"
+)
+
+// GhostBackupStatus defines the observed state of GhostBackup
+type GhostBackupStatus struct {
+	// Phase of the backup
+	// +optional
+	Phase GhostBackupPhase `json:"phase,omitempty"`
+
+	// Message explains the phase
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// JobName is the Job taking the backup
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// Location of the archive, an s3:// or pvc:// url
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// Size of the archive in bytes
+	// +optional
+	Size int64 `json:"size,omitempty"`
+
+	// Checksum of the archive, sha256:<hex>
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+
+	// StartTime is when the Job taking the backup was created
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the backup succeeded or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ghost",type=string,JSONPath=`.spec.ghostName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.status.size`
+// +kubebuilder:printcolumn:name="Location",type=string,JSONPath=`.status.location`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GhostBackup is the Schema for the ghostbackups API
+type GhostBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GhostBackupSpec   `json:"spec,omitempty"`
+	Status GhostBackupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GhostBackupList contains a list of GhostBackup
+type GhostBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GhostBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GhostBackup{}, &GhostBackupList{})
+}
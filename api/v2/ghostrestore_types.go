@@ -0,0 +1,159 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// GhostRestoreSpec defines the desired state of GhostRestore
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+type GhostRestoreSpec struct {
+	// GhostName is the Ghost in the namespace of the restore the archive is
+	// restored into. Its content and database are replaced.
+	// +kubebuilder:validation:MinLength=1
+	GhostName string `json:"ghostName"`
+
+	// Source of the archive to restore
+	Source RestoreSource `json:"source"`
+}
+
+// RestoreSource defines the archive a restore starts from. Exactly one of
+// backupName and archive has to be set.
+// +kubebuilder:validation:XValidation:rule="has(self.backupName) != has(self.archive)",message="exactly one of backupName and archive is required"
+type RestoreSource struct {
+	// BackupName is a GhostBackup in the namespace of the restore
+	// +optional
+	BackupName string `json:"backupName,omitempty"`
+
+	// Archive is an archive stored by a GhostBackup of another namespace or
+	// cluster, to restore into a fresh namespace for instance
+	// +optional
+	Archive *RestoreArchive `json:"archive,omitempty"`
+}
+
+// RestoreArchive defines where an archive is stored
+// +kubebuilder:validation:XValidation:rule="!self.url.startsWith('s3://') || has(self.credentialsSecretRef)",message="credentialsSecretRef is required for s3 archives"
+type RestoreArchive struct {
+	// URL of the archive, as reported in the location of a GhostBackup:
+	// s3://<bucket>/<key>, or pvc://<claim>/<path> for a PVC in the namespace
+	// of the restore
+	// +kubebuilder:validation:Pattern=`^(s3|pvc)://[^/]+/.+$`
+	URL string `json:"url"`
+
+	// Endpoint of the object storage, e.g. http://minio.minio.svc:9000.
+	// AWS S3 is used when it is left empty.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Region of the bucket
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// CredentialsSecretRef is a Secret in the namespace of the restore holding
+	// the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY of the bucket
+	// +optional
+	CredentialsSecretRef *corev1.LocalObjectReference `json:"credentialsSecretRef,omitempty"`
+
+	// Checksum of the archive, sha256:<hex>. The archive is verified against
+	// it before anything is restored.
+	// +kubebuilder:validation:Pattern=`^sha256:[0-9a-f]{64}$`
+	// +optional
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// GhostRestorePhase is the stage a restore is in
+type GhostRestorePhase string
+
+const (
+	// GhostRestorePhasePending restores wait for their archive and Ghost
+	GhostRestorePhasePending GhostRestorePhase = "Pending"
+	// GhostRestorePhaseScalingDown restores wait for the Ghost pods to stop
+	GhostRestorePhaseScalingDown GhostRestorePhase = "ScalingDown"
+	// GhostRestorePhaseRestoring restores have a Job repopulating the content and database
+	GhostRestorePhaseRestoring GhostRestorePhase = "Restoring"
+	// GhostRestorePhaseMigrating restores have a Job migrating the database to the Ghost image
+	GhostRestorePhaseMigrating GhostRestorePhase = "Migrating"
+	// GhostRestorePhaseScalingUp restores wait for the Ghost pods to be available again
+	GhostRestorePhaseScalingUp GhostRestorePhase = "ScalingUp"
+	// GhostRestorePhaseSucceeded restores have the Ghost serving the archive
+	GhostRestorePhaseSucceeded GhostRestorePhase = "Succeeded"
+	// GhostRestorePhaseFailed restores could not be completed
+	GhostRestorePhaseFailed GhostRestorePhase = "Failed"
+)
+
+// GhostRestoreStatus defines the observed state of GhostRestore
+type GhostRestoreStatus struct {
+	// Phase of the restore
+	// +optional
+	Phase GhostRestorePhase `json:"phase,omitempty"`
+
+	// Message explains the phase
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Location of the archive being restored, an s3:// or pvc:// url
+	// +optional
+	Location string `json:"location,omitempty"`
+
+	// JobName is the Job restoring the archive
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// MigrationJobName is the Job migrating the restored database
+	// +optional
+	MigrationJobName string `json:"migrationJobName,omitempty"`
+
+	// StartTime is when the Ghost was scaled down
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is when the restore succeeded or failed
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ghost",type=string,JSONPath=`.spec.ghostName`
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Location",type=string,JSONPath=`.status.location`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// GhostRestore is the Schema for the ghostrestores API
+type GhostRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GhostRestoreSpec   `json:"spec,omitempty"`
+	Status GhostRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GhostRestoreList contains a list of GhostRestore
+type GhostRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GhostRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&GhostRestore{}, &GhostRestoreList{})
+}
@@ -61,3 +61,16 @@ func GetServiceFromFile(name string) (*corev1.Service, error) {
 	service := serviceObject.(*corev1.Service)
 	return service, nil
 }
+
+func GetStatefulSetFromFile(name string) (*appsv1.StatefulSet, error) {
+	statefulSetBytes, err := manifests.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	statefulSetObject, err := runtime.Decode(assetsCodecs.UniversalDecoder(appsv1.SchemeGroupVersion), statefulSetBytes)
+	if err != nil {
+		return nil, err
+	}
+	statefulSet := statefulSetObject.(*appsv1.StatefulSet)
+	return statefulSet, nil
+}
@@ -0,0 +1,363 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	blogv2 "example.com/api/v2"
+)
+
+const (
+	backupJobNamePrefix = "ghost-backup-"
+	pruneJobNamePrefix  = "ghost-prune-"
+)
+
+// backupArchiveFinalizer deletes the archive of a scheduled backup from its
+// target when the backup expires
+const backupArchiveFinalizer = "blog.example.com/archive"
+
+// Images of the backup Job steps
+const (
+	backupSQLiteImage = "keinos/sqlite3:3.46.1"
+	backupMySQLImage  = "mysql:8.0"
+	backupToolsImage  = "busybox:1.36"
+	backupS3Image     = "amazon/aws-cli:2.17.0"
+)
+
+// Mount paths of the backup Job. The content volume is mounted inside the
+// work directory, so it is archived next to the database dump.
+const (
+	backupWorkPath    = "/work"
+	backupArchivePath = "/archive"
+	backupTargetPath  = "/target"
+)
+
+// backupUploadContainer is the last step of the backup Job. Its termination
+// message reports the size and checksum of the archive.
+const backupUploadContainer = "upload"
+
+// backupResult is the termination message of the upload step
+type backupResult struct {
+	Size     int64  `json:"size"`
+	Checksum string `json:"checksum"`
+}
+
+// boundedName joins prefix and name, shortening the result with a hash of
+// name when it would not fit a label value.
+func boundedName(prefix, name string) string {
+	const maxLength = 63
+	if len(prefix)+len(name) <= maxLength {
+		return prefix + name
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(sum[:])[:8]
+	return prefix + name[:maxLength-len(prefix)-len(suffix)-1] + "-" + suffix
+}
+
+// backupArchiveKey returns the path of the archive of a backup relative to
+// the target, grouped by namespace and Ghost.
+func backupArchiveKey(backup *blogv2.GhostBackup, prefix string) string {
+	return path.Join(strings.Trim(prefix, "/"), backup.Namespace, backup.Spec.GhostName, backup.Name+".tar.gz")
+}
+
+// backupTargetKey returns the path of the archive of a backup in its target
+func backupTargetKey(backup *blogv2.GhostBackup) string {
+	if s3 := backup.Spec.Target.S3; s3 != nil {
+		return backupArchiveKey(backup, s3.Prefix)
+	}
+	return backupArchiveKey(backup, backup.Spec.Target.PVC.Path)
+}
+
+// backupLocation returns the url of the archive of a backup
+func backupLocation(backup *blogv2.GhostBackup) string {
+	return archiveLocation(backup.Spec.Target, backupTargetKey(backup))
+}
+
+// archiveLocation returns the url of the archive at key in target
+func archiveLocation(target blogv2.BackupTarget, key string) string {
+	if target.S3 != nil {
+		return "s3://" + target.S3.Bucket + "/" + key
+	}
+	return "pvc://" + target.PVC.ClaimName + "/" + key
+}
+
+// generateBackupJob returns the Job archiving the content and database of the
+// Ghost to the target of the backup. The database is dumped online, with the
+// SQLite backup API or a single transaction mysqldump, so Ghost keeps serving
+// while a consistent copy is taken. The Job runs next to the Ghost pods when
+// they hold a ReadWriteOnce content volume.
+func generateBackupJob(backup *blogv2.GhostBackup, ghost *blogv2.Ghost, claimName string, nextToGhost bool) *batchv1.Job {
+	backoffLimit := int32(2)
+	uid := ghostUserID
+	var affinity *corev1.Affinity
+	if nextToGhost {
+		affinity = ghostPodAffinity(ghost)
+	}
+	contentMount := corev1.VolumeMount{Name: "content", MountPath: backupWorkPath + "/content", ReadOnly: true}
+	workMount := corev1.VolumeMount{Name: "work", MountPath: backupWorkPath}
+	archiveMount := corev1.VolumeMount{Name: "archive", MountPath: backupArchivePath}
+
+	volumes := []corev1.Volume{
+		{Name: "content", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName}}},
+		{Name: "work", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		{Name: "archive", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}
+
+	archive := corev1.Container{
+		Name:    "archive",
+		Image:   backupToolsImage,
+		Command: []string{"sh", "-ec"},
+		Args: []string{`cd ` + backupWorkPath + `
+tar -czf ` + backupArchivePath + `/backup.tar.gz --exclude 'content/data/ghost.db*' --exclude 'content/data/.ghost.db-litestream' content database.*
+size=$(wc -c < ` + backupArchivePath + `/backup.tar.gz)
+checksum=$(sha256sum ` + backupArchivePath + `/backup.tar.gz | cut -d ' ' -f 1)
+printf '{"size":%s,"checksum":"sha256:%s"}' "$size" "$checksum" > ` + backupArchivePath + `/result.json`},
+		VolumeMounts: []corev1.VolumeMount{workMount, contentMount, archiveMount},
+	}
+
+	upload := backupUploadStep(backup)
+	upload.VolumeMounts = append(upload.VolumeMounts, archiveMount)
+	volumes = append(volumes, backupTargetVolumes(backup.Spec.Target)...)
+
+	labels := labelsForGhost(ghost)
+	labels[nameLabel] = "ghost-backup"
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      boundedName(backupJobNamePrefix, backup.Name),
+			Namespace: backup.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:  &uid,
+						RunAsGroup: &uid,
+						FSGroup:    &uid,
+					},
+					Affinity:       affinity,
+					InitContainers: []corev1.Container{backupDumpStep(ghost, workMount, contentMount), archive},
+					Containers:     []corev1.Container{upload},
+					Volumes:        volumes,
+				},
+			},
+		},
+	}
+}
+
+// ghostPodAffinity requires the nodes the Ghost pods run on
+func ghostPodAffinity(ghost *blogv2.Ghost) *corev1.Affinity {
+	return &corev1.Affinity{
+		PodAffinity: &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: selectorForGhost(ghost)},
+				TopologyKey:   corev1.LabelHostname,
+			}},
+		},
+	}
+}
+
+// ghostPodsHoldVolume reports whether Ghost pods run with the content volume
+// mounted while it is ReadWriteOnce. Such a volume can only be mounted again
+// on the same node, so Jobs reading it have to run there.
+func ghostPodsHoldVolume(ctx context.Context, c client.Reader, ghost *blogv2.Ghost, claimName string) (bool, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: ghost.Namespace, Name: claimName}, pvc); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	for _, mode := range pvc.Spec.AccessModes {
+		if mode == corev1.ReadWriteMany || mode == corev1.ReadOnlyMany {
+			return false, nil
+		}
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods, client.InNamespace(ghost.Namespace), client.MatchingLabels(selectorForGhost(ghost))); err != nil {
+		return false, err
+	}
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName != "" && pod.DeletionTimestamp.IsZero() &&
+			pod.Status.Phase != corev1.PodSucceeded && pod.Status.Phase != corev1.PodFailed {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// backupDumpStep dumps the database of the Ghost into the work directory
+func backupDumpStep(ghost *blogv2.Ghost, workMount, contentMount corev1.VolumeMount) corev1.Container {
+	if ghost.Spec.DatabaseClient() != blogv2.DatabaseClientMySQL {
+		return corev1.Container{
+			Name:         "dump-database",
+			Image:        backupSQLiteImage,
+			Command:      []string{"sh", "-ec"},
+			Args:         []string{`sqlite3 "file:` + backupWorkPath + `/content/data/ghost.db?mode=ro" ".backup ` + backupWorkPath + `/database.sqlite"`},
+			VolumeMounts: []corev1.VolumeMount{workMount, contentMount},
+		}
+	}
+
+	return corev1.Container{
+		Name:    "dump-database",
+		Image:   backupMySQLImage,
+		Command: []string{"sh", "-ec"},
+		Args: []string{`mysqldump --single-transaction --routines --no-tablespaces -h "$DB_HOST" -P "$DB_PORT" -u "$DB_USER" "$DB_NAME" > ` +
+			backupWorkPath + `/database.sql`},
+		Env:          mysqlClientEnv(ghost),
+		VolumeMounts: []corev1.VolumeMount{workMount},
+	}
+}
+
+// mysqlClientEnv returns the connection settings of the MySQL database of the
+// Ghost in the variables the scripts of the backup and restore Jobs use.
+func mysqlClientEnv(ghost *blogv2.Ghost) []corev1.EnvVar {
+	db := databaseConnection(ghost)
+	env := []corev1.EnvVar{}
+	for _, e := range databaseEnvVars(ghost) {
+		switch e.Name {
+		case "database__connection__host":
+			env = append(env, corev1.EnvVar{Name: "DB_HOST", Value: e.Value})
+		case "database__connection__port":
+			env = append(env, corev1.EnvVar{Name: "DB_PORT", Value: e.Value})
+		case "database__connection__user":
+			env = append(env, corev1.EnvVar{Name: "DB_USER", Value: e.Value, ValueFrom: e.ValueFrom})
+		case "database__connection__database":
+			env = append(env, corev1.EnvVar{Name: "DB_NAME", Value: e.Value})
+		}
+	}
+	if db.PasswordSecretRef != nil {
+		env = append(env, corev1.EnvVar{Name: "MYSQL_PWD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: db.PasswordSecretRef}})
+	}
+	return env
+}
+
+// backupUploadStep copies the archive to the target of the backup and
+// reports its size and checksum in its termination message.
+func backupUploadStep(backup *blogv2.GhostBackup) corev1.Container {
+	report := `cat ` + backupArchivePath + `/result.json > /dev/termination-log`
+	return backupTargetStep(backup.Spec.Target, backupTargetKey(backup), backupUploadContainer,
+		`aws s3 cp --only-show-errors `+backupArchivePath+`/backup.tar.gz "$ARCHIVE_URL"`+"\n"+report,
+		`mkdir -p "$(dirname "$ARCHIVE_PATH")"
+cp `+backupArchivePath+`/backup.tar.gz "$ARCHIVE_PATH.tmp"
+mv "$ARCHIVE_PATH.tmp" "$ARCHIVE_PATH"
+`+report)
+}
+
+// backupTargetStep returns a container running a script against the archive
+// at key in target: s3Script with the aws cli and the archive at $ARCHIVE_URL,
+// or pvcScript with the archive at $ARCHIVE_PATH on the target volume.
+func backupTargetStep(target blogv2.BackupTarget, key, name, s3Script, pvcScript string) corev1.Container {
+	// The aws cli keeps its configuration in HOME
+	tmpMount := corev1.VolumeMount{Name: "tmp", MountPath: "/tmp"}
+
+	if s3 := target.S3; s3 != nil {
+		env := []corev1.EnvVar{
+			{Name: "HOME", Value: "/tmp"},
+			{Name: "ARCHIVE_URL", Value: archiveLocation(target, key)},
+		}
+		if s3.Endpoint != "" {
+			// Compatible storages like MinIO are addressed by path
+			env = append(env, corev1.EnvVar{Name: "AWS_ENDPOINT_URL", Value: s3.Endpoint})
+			s3Script = `aws configure set default.s3.addressing_style path` + "\n" + s3Script
+		}
+		if s3.Region != "" {
+			env = append(env, corev1.EnvVar{Name: "AWS_DEFAULT_REGION", Value: s3.Region})
+		}
+		return corev1.Container{
+			Name:         name,
+			Image:        backupS3Image,
+			Command:      []string{"sh", "-ec"},
+			Args:         []string{s3Script},
+			Env:          env,
+			EnvFrom:      []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: s3.CredentialsSecretRef}}},
+			VolumeMounts: []corev1.VolumeMount{tmpMount},
+		}
+	}
+
+	return corev1.Container{
+		Name:         name,
+		Image:        backupToolsImage,
+		Command:      []string{"sh", "-ec"},
+		Args:         []string{pvcScript},
+		Env:          []corev1.EnvVar{{Name: "ARCHIVE_PATH", Value: backupTargetPath + "/" + key}},
+		VolumeMounts: []corev1.VolumeMount{tmpMount, {Name: "target", MountPath: backupTargetPath}},
+	}
+}
+
+// backupTargetVolumes returns the volumes mounted by backupTargetStep
+func backupTargetVolumes(target blogv2.BackupTarget) []corev1.Volume {
+	volumes := []corev1.Volume{{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	if pvc := target.PVC; pvc != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name:         "target",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.ClaimName}},
+		})
+	}
+	return volumes
+}
+
+// parseBackupResult reads the size and checksum reported by the upload step
+func parseBackupResult(message string) (backupResult, error) {
+	result := backupResult{}
+	err := json.Unmarshal([]byte(message), &result)
+	return result, err
+}
+
+// generatePruneJob returns the Job deleting the archive of a backup from its target
+func generatePruneJob(backup *blogv2.GhostBackup) *batchv1.Job {
+	backoffLimit := int32(2)
+	uid := ghostUserID
+	labels := map[string]string{
+		nameLabel:        "ghost-backup",
+		instanceLabel:    backup.Spec.GhostName,
+		managedByLabel:   "ghost-operator",
+		backupGhostLabel: backup.Spec.GhostName,
+	}
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      boundedName(pruneJobNamePrefix, backup.Name),
+			Namespace: backup.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy:   corev1.RestartPolicyNever,
+					SecurityContext: &corev1.PodSecurityContext{RunAsUser: &uid, RunAsGroup: &uid, FSGroup: &uid},
+					Containers: []corev1.Container{backupTargetStep(backup.Spec.Target, backupTargetKey(backup), "prune",
+						`aws s3 rm --only-show-errors "$ARCHIVE_URL"`, `rm -f "$ARCHIVE_PATH"`)},
+					Volumes: backupTargetVolumes(backup.Spec.Target),
+				},
+			},
+		},
+	}
+}
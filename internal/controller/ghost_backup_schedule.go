@@ -0,0 +1,284 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	blogv2 "example.com/api/v2"
+)
+
+// Labels of the GhostBackups taken on the schedule of a Ghost
+const (
+	backupGhostLabel     = "blog.example.com/ghost"
+	backupScheduledLabel = "blog.example.com/scheduled"
+)
+
+// conditionBackupFailing is True once several scheduled backups failed in a row
+const conditionBackupFailing = "BackupFailing"
+
+// backupFailureThreshold is the number of scheduled backups failing in a row
+// that raises the BackupFailing condition
+const backupFailureThreshold = 3
+
+// defaultKeepLast is the number of scheduled backups kept without retention rules
+const defaultKeepLast = 7
+
+// ghostBackups returns the GhostBackups of the Ghost, the most recent first
+func (r *GhostReconciler) ghostBackups(ctx context.Context, ghost *blogv2.Ghost) ([]blogv2.GhostBackup, error) {
+	list := &blogv2.GhostBackupList{}
+	if err := r.List(ctx, list, client.InNamespace(ghost.Namespace)); err != nil {
+		return nil, err
+	}
+	var backups []blogv2.GhostBackup
+	for _, backup := range list.Items {
+		if backup.Spec.GhostName == ghost.Name {
+			backups = append(backups, backup)
+		}
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		return backups[j].CreationTimestamp.Before(&backups[i].CreationTimestamp)
+	})
+	return backups, nil
+}
+
+// reconcileBackupSchedule takes the GhostBackups due on the schedule of the
+// Ghost, prunes the expired ones and reports the backups in the status. It
+// returns how long to wait for the next scheduled backup, zero without one.
+func (r *GhostReconciler) reconcileBackupSchedule(ctx context.Context, ghost *blogv2.Ghost) (time.Duration, error) {
+	log := log.FromContext(ctx)
+
+	backups, err := r.ghostBackups(ctx, ghost)
+	if err != nil {
+		return 0, err
+	}
+	ghost.Status.LastSuccessfulBackup = nil
+	for _, backup := range backups {
+		if backup.Status.Phase == blogv2.GhostBackupPhaseSucceeded {
+			ghost.Status.LastSuccessfulBackup = backup.CreationTimestamp.DeepCopy()
+			break
+		}
+	}
+
+	var scheduled []blogv2.GhostBackup
+	for _, backup := range backups {
+		if backup.Labels[backupScheduledLabel] == "true" && backup.DeletionTimestamp.IsZero() {
+			scheduled = append(scheduled, backup)
+		}
+	}
+	updateBackupFailingCondition(ghost, scheduled)
+
+	spec := ghost.Spec.Backup
+	if spec == nil {
+		ghost.Status.NextScheduledBackup = nil
+		return 0, nil
+	}
+	schedule, err := cron.ParseStandard(spec.Schedule)
+	if err != nil {
+		addCondition(ghost, conditionBackupFailing, metav1.ConditionTrue, "InvalidSchedule", "Invalid backup schedule: "+err.Error())
+		ghost.Status.NextScheduledBackup = nil
+		return 0, nil
+	}
+
+	if err := r.pruneBackups(ctx, ghost, scheduled); err != nil {
+		return 0, err
+	}
+	if spec.Suspend {
+		ghost.Status.NextScheduledBackup = nil
+		return 0, nil
+	}
+
+	// Catch up on a missed schedule with a single backup
+	now := time.Now()
+	last := ghost.CreationTimestamp.Time
+	if len(scheduled) > 0 {
+		last = scheduled[0].CreationTimestamp.Time
+	}
+	var due time.Time
+	next := schedule.Next(last)
+	for i := 0; !next.After(now) && i < 10000; i++ {
+		due = next
+		next = schedule.Next(next)
+	}
+
+	if !due.IsZero() {
+		if len(scheduled) > 0 && !backupFinished(&scheduled[0]) {
+			log.Info("Scheduled backup skipped, the previous one is still running", "backup", scheduled[0].Name)
+		} else if err := r.createScheduledBackup(ctx, ghost, due); err != nil {
+			return 0, err
+		}
+	}
+
+	nextTime := metav1.NewTime(next)
+	ghost.Status.NextScheduledBackup = &nextTime
+	return next.Sub(now), nil
+}
+
+// createScheduledBackup creates the GhostBackup of the schedule at time due
+func (r *GhostReconciler) createScheduledBackup(ctx context.Context, ghost *blogv2.Ghost, due time.Time) error {
+	backup := &blogv2.GhostBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%d", ghost.Name, due.Unix()),
+			Namespace: ghost.Namespace,
+			Labels: map[string]string{
+				backupGhostLabel:     ghost.Name,
+				backupScheduledLabel: "true",
+			},
+			// The archive goes along with the backup once it expires
+			Finalizers: []string{backupArchiveFinalizer},
+		},
+		Spec: blogv2.GhostBackupSpec{
+			GhostName: ghost.Name,
+			Target:    *ghost.Spec.Backup.Target.DeepCopy(),
+		},
+	}
+	if err := r.Create(ctx, backup); err != nil {
+		if errors.IsAlreadyExists(err) {
+			return nil
+		}
+		return err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "BackupScheduled", "GhostBackup "+backup.Name+" created")
+	log.FromContext(ctx).Info("Scheduled backup created", "backup", backup.Name)
+	return nil
+}
+
+// updateBackupFailingCondition raises BackupFailing once the most recent
+// scheduled backups failed in a row, and clears it after a success.
+func updateBackupFailingCondition(ghost *blogv2.Ghost, scheduled []blogv2.GhostBackup) {
+	failures := 0
+	for _, backup := range scheduled {
+		if backup.Status.Phase == blogv2.GhostBackupPhaseFailed {
+			failures++
+			continue
+		}
+		if backup.Status.Phase == blogv2.GhostBackupPhaseSucceeded {
+			break
+		}
+	}
+	if failures < backupFailureThreshold {
+		meta.RemoveStatusCondition(&ghost.Status.Conditions, conditionBackupFailing)
+		return
+	}
+	addCondition(ghost, conditionBackupFailing, metav1.ConditionTrue, "ConsecutiveFailures",
+		fmt.Sprintf("The last %d scheduled backups failed", failures))
+}
+
+// pruneBackups deletes the scheduled backups the retention of the Ghost does
+// not keep. The most recent failed backups are kept until a newer backup
+// succeeded, so they still count towards the BackupFailing condition, but no
+// more of them than the condition needs.
+func (r *GhostReconciler) pruneBackups(ctx context.Context, ghost *blogv2.Ghost, scheduled []blogv2.GhostBackup) error {
+	keep := retainedBackups(ghost.Spec.Backup.Retention, scheduled)
+	succeeded := false
+	failed := 0
+	for i := range scheduled {
+		backup := &scheduled[i]
+		switch backup.Status.Phase {
+		case blogv2.GhostBackupPhaseSucceeded:
+			succeeded = true
+			if keep[backup.Name] {
+				continue
+			}
+		case blogv2.GhostBackupPhaseFailed:
+			failed++
+			if !succeeded && failed <= backupFailureThreshold {
+				continue
+			}
+		default:
+			continue
+		}
+		if err := r.Delete(ctx, backup); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "BackupPruned", "GhostBackup "+backup.Name+" expired")
+		log.FromContext(ctx).Info("Scheduled backup pruned", "backup", backup.Name)
+	}
+	return nil
+}
+
+// retainedBackups returns the names of the successful backups selected by the
+// retention rules. The backups are sorted the most recent first.
+func retainedBackups(retention blogv2.BackupRetention, backups []blogv2.GhostBackup) map[string]bool {
+	keepLast := retention.KeepLast
+	if keepLast == nil && retention.KeepDaily == nil && retention.KeepWeekly == nil && retention.KeepMonthly == nil {
+		last := int32(defaultKeepLast)
+		keepLast = &last
+	}
+
+	rules := []struct {
+		keep   *int32
+		bucket func(time.Time) string
+	}{
+		{keepLast, nil},
+		{retention.KeepDaily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{retention.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%d", year, week)
+		}},
+		{retention.KeepMonthly, func(t time.Time) string { return t.Format("2006-01") }},
+	}
+
+	keep := map[string]bool{}
+	for _, rule := range rules {
+		if rule.keep == nil {
+			continue
+		}
+		buckets := map[string]bool{}
+		for _, backup := range backups {
+			if len(buckets) >= int(*rule.keep) {
+				break
+			}
+			if backup.Status.Phase != blogv2.GhostBackupPhaseSucceeded {
+				continue
+			}
+			// Every backup is a bucket of its own for keepLast
+			bucket := backup.Name
+			if rule.bucket != nil {
+				bucket = rule.bucket(backup.CreationTimestamp.UTC())
+			}
+			if !buckets[bucket] {
+				buckets[bucket] = true
+				keep[backup.Name] = true
+			}
+		}
+	}
+	return keep
+}
+
+// ghostForBackup maps a GhostBackup to the Ghost it backs up, so the status
+// of the Ghost follows its backups.
+func ghostForBackup(ctx context.Context, obj client.Object) []reconcile.Request {
+	backup, ok := obj.(*blogv2.GhostBackup)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: backup.Namespace, Name: backup.Spec.GhostName}}}
+}
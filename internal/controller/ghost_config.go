@@ -0,0 +1,144 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	blogv2 "example.com/api/v2"
+)
+
+// configEnvPrefixes are the settings rendered from spec.config, the url
+// aside. Ghost reads nested config keys from __ delimited env vars.
+var configEnvPrefixes = []string{"admin__", "mail__", "privacy__", "logging__", "storage__"}
+
+// configEnvVars returns the env vars rendering the site configuration of the Ghost.
+func configEnvVars(ghost *blogv2.Ghost) []corev1.EnvVar {
+	config := ghost.Spec.Config
+	var env []corev1.EnvVar
+
+	if config.Admin != nil && config.Admin.URL != "" {
+		env = append(env, corev1.EnvVar{Name: "admin__url", Value: config.Admin.URL})
+	}
+
+	if mail := config.Mail; mail != nil {
+		transport := mail.Transport
+		if transport == "" {
+			transport = blogv2.MailTransportSMTP
+		}
+		env = append(env, corev1.EnvVar{Name: "mail__transport", Value: string(transport)})
+		if mail.From != "" {
+			env = append(env, corev1.EnvVar{Name: "mail__from", Value: mail.From})
+		}
+		if smtp := mail.SMTP; smtp != nil && transport == blogv2.MailTransportSMTP {
+			port := smtp.Port
+			if port == 0 {
+				port = 587
+				if smtp.Secure {
+					port = 465
+				}
+			}
+			env = append(env,
+				corev1.EnvVar{Name: "mail__options__host", Value: smtp.Host},
+				corev1.EnvVar{Name: "mail__options__port", Value: strconv.Itoa(int(port))},
+				corev1.EnvVar{Name: "mail__options__secure", Value: strconv.FormatBool(smtp.Secure)},
+			)
+			if smtp.UserSecretRef != nil {
+				env = append(env, corev1.EnvVar{
+					Name:      "mail__options__auth__user",
+					ValueFrom: &corev1.EnvVarSource{SecretKeyRef: smtp.UserSecretRef},
+				})
+			}
+			if smtp.PasswordSecretRef != nil {
+				env = append(env, corev1.EnvVar{
+					Name:      "mail__options__auth__pass",
+					ValueFrom: &corev1.EnvVarSource{SecretKeyRef: smtp.PasswordSecretRef},
+				})
+			}
+		}
+	}
+
+	if privacy := config.Privacy; privacy != nil {
+		for _, toggle := range []struct {
+			name  string
+			value *bool
+		}{
+			{"privacy__useTinfoil", privacy.UseTinfoil},
+			{"privacy__useUpdateCheck", privacy.UseUpdateCheck},
+			{"privacy__useGravatar", privacy.UseGravatar},
+			{"privacy__useRpcPing", privacy.UseRpcPing},
+			{"privacy__useStructuredData", privacy.UseStructuredData},
+		} {
+			if toggle.value != nil {
+				env = append(env, corev1.EnvVar{Name: toggle.name, Value: strconv.FormatBool(*toggle.value)})
+			}
+		}
+	}
+
+	if logging := config.Logging; logging != nil {
+		if logging.Level != "" {
+			env = append(env, corev1.EnvVar{Name: "logging__level", Value: logging.Level})
+		}
+		if len(logging.Transports) > 0 {
+			// Ghost parses env values, so a JSON array becomes a list
+			transports, _ := json.Marshal(logging.Transports)
+			env = append(env, corev1.EnvVar{Name: "logging__transports", Value: string(transports)})
+		}
+	}
+
+	if ghost.Spec.Storage.Adapter != "" {
+		env = append(env, corev1.EnvVar{Name: "storage__active", Value: ghost.Spec.Storage.Adapter})
+	}
+
+	return env
+}
+
+// withConfigEnv replaces the settings rendered from the site configuration
+// in env with the ones of the Ghost. The extra env vars come last and replace
+// any variable of the same name.
+func withConfigEnv(ghost *blogv2.Ghost, env []corev1.EnvVar) []corev1.EnvVar {
+	extra := map[string]bool{}
+	for _, e := range ghost.Spec.Config.ExtraEnv {
+		extra[e.Name] = true
+	}
+
+	result := make([]corev1.EnvVar, 0, len(env))
+	for _, e := range env {
+		if !isConfigEnv(e.Name) && !extra[e.Name] {
+			result = append(result, e)
+		}
+	}
+	for _, e := range configEnvVars(ghost) {
+		if !extra[e.Name] {
+			result = append(result, e)
+		}
+	}
+	return append(result, ghost.Spec.Config.ExtraEnv...)
+}
+
+func isConfigEnv(name string) bool {
+	for _, prefix := range configEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -18,21 +18,37 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
 	"example.com/assets"
 	appsv1 "k8s.io/api/apps/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
-	blogv1 "example.com/api/v1"
+	blogv2 "example.com/api/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 )
 
 // GhostReconciler reconciles a Ghost object
@@ -46,6 +62,21 @@ const pvcNamePrefix = "ghost-data-pvc-"
 const deploymentNamePrefix = "ghost-deployment-"
 const svcNamePrefix = "ghost-service-"
 
+// specHashAnnotation records the hash of the desired spec a child was last written from
+const specHashAnnotation = "blog.example.com/spec-hash"
+
+// managedAnnotationsAnnotation lists the annotations a child got from the
+// Ghost, so the ones the Ghost stops declaring are removed again
+const managedAnnotationsAnnotation = "blog.example.com/managed-annotations"
+
+// Labels used to select the children of a single Ghost. Every child object and
+// pod template carries them, so several Ghosts can share a namespace.
+const (
+	nameLabel      = "app.kubernetes.io/name"
+	instanceLabel  = "app.kubernetes.io/instance"
+	managedByLabel = "app.kubernetes.io/managed-by"
+)
+
 // +kubebuilder:rbac:groups=blog.example.com,resources=ghosts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=blog.example.com,resources=ghosts/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=blog.example.com,resources=ghosts/finalizers,verbs=update
@@ -53,22 +84,28 @@ const svcNamePrefix = "ghost-service-"
 //+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
-
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the Ghost object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.19.0/pkg/reconcile
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=blog.example.com,resources=ghostbackups;ghostrestores,verbs=get;list;watch;create
+
+// Reconcile brings the children of a Ghost in line with its spec. It adds the
+// finalizer, validates the spec for its environment, migrates the children of
+// older operator versions, then reconciles the PVC, the database, an image
+// upgrade, the Deployment, the Service, Ingress and HTTPRoute exposing it and
+// the backup schedule, and finally writes the status.
 func (r *GhostReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Get a logger instance
 	log := log.FromContext(ctx)
 
 	// Get a ptr to a Ghost instance
-	ghost := &blogv1.Ghost{}
+	ghost := &blogv2.Ghost{}
 
 	// Using the Namespaced Name, let's get the resource into our ptr to a Ghost struct
 	if err := r.Get(ctx, req.NamespacedName, ghost); err != nil {
@@ -76,47 +113,176 @@ func (r *GhostReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// Apply the retention policy before the children are garbage collected
+	if !ghost.ObjectMeta.DeletionTimestamp.IsZero() {
+		return r.finalizeGhost(ctx, ghost)
+	}
+	if controllerutil.AddFinalizer(ghost, ghostFinalizer) {
+		if err := r.Update(ctx, ghost); err != nil {
+			log.Error(err, "Failed to add finalizer to Ghost")
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Refuse to roll out a spec that cannot run in its environment
+	if reason, err := validateEnvironment(ghost); err != nil {
+		log.Info("Ghost spec is not valid", "reason", reason, "message", err.Error())
+		if !meta.IsStatusConditionFalse(ghost.Status.Conditions, conditionValidated) {
+			r.recoder.Event(ghost, corev1.EventTypeWarning, "ValidationFailed", err.Error())
+		}
+		addCondition(ghost, conditionValidated, metav1.ConditionFalse, reason, err.Error())
+		addCondition(ghost, "GhostReady", metav1.ConditionFalse, "ValidationFailed", "The Ghost spec is not valid")
+		if err := r.updateStatus(ctx, ghost); err != nil {
+			log.Error(err, "Failed to update Ghost status")
+			return ctrl.Result{}, err
+		}
+		// Wait for the spec to be fixed
+		return ctrl.Result{}, nil
+	}
+	addCondition(ghost, conditionValidated, metav1.ConditionTrue, "Valid", "The Ghost spec is valid for the "+string(ghostEnvironment(ghost))+" environment")
+
 	// Initialize completion status flags
-	// Add or update the namespace first
 	pvcReady := false
 	deploymentReady := false
 	serviceReady := false
 
-	// Output the ImageTag for the Ghost struct
-	log.Info("Reconciling Ghost", "imageTag", ghost.Spec.ImageTag, "team", ghost.ObjectMeta.Namespace)
+	// Output the image for the Ghost struct
+	log.Info("Reconciling Ghost", "image", ghost.Spec.ImageReference(), "team", ghost.ObjectMeta.Namespace)
+
+	// Move children created by older operator versions over to the per-Ghost names
+	if err := r.migrateLegacyResources(ctx, ghost); err != nil {
+		log.Error(err, "Failed to migrate legacy resources for Ghost")
+		return ctrl.Result{}, err
+	}
 
 	// Add or update PVC
-	if err := r.addPvcIfNotExists(ctx, ghost); err != nil {
+	storageResizing, err := r.addOrUpdatePvc(ctx, ghost)
+	if err != nil {
 		log.Error(err, "Failed to add PVC for Ghost")
-		addCondition(&ghost.Status, "PVCNotReady", metav1.ConditionFalse, "PVCNotReady", "Failed to add PVC for Ghost")
+		addCondition(ghost, "PVCNotReady", metav1.ConditionFalse, "PVCNotReady", "Failed to add PVC for Ghost")
 		return ctrl.Result{}, err
+	}
+	pvcReady = true
+	meta.RemoveStatusCondition(&ghost.Status.Conditions, "PVCNotReady")
+
+	// Check the database settings before rolling them out
+	if reason, err := r.checkDatabase(ctx, ghost); err != nil {
+		log.Error(err, "Database for Ghost is not ready")
+		addCondition(ghost, "DatabaseReady", metav1.ConditionFalse, reason, err.Error())
+		if err := r.updateStatus(ctx, ghost); err != nil {
+			log.Error(err, "Failed to update Ghost status")
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Add the managed database, Ghost waits for it in an init container
+	databaseReady := true
+	if ghost.Spec.Database.Managed {
+		ready, err := r.addOrUpdateManagedDatabase(ctx, ghost)
+		if err != nil {
+			log.Error(err, "Failed to add managed database for Ghost")
+			addCondition(ghost, "DatabaseReady", metav1.ConditionFalse, "MySQLNotReady", "Failed to add managed MySQL for Ghost")
+			return ctrl.Result{}, err
+		}
+		databaseReady = ready
+		if databaseReady {
+			addCondition(ghost, "DatabaseReady", metav1.ConditionTrue, "MySQLReady", "Managed MySQL is ready")
+		} else {
+			addCondition(ghost, "DatabaseReady", metav1.ConditionFalse, "MySQLStarting", "Waiting for managed MySQL to become ready")
+		}
 	} else {
-		pvcReady = true
+		addCondition(ghost, "DatabaseReady", metav1.ConditionTrue, "DatabaseConfigured", "Database "+string(ghost.Spec.DatabaseClient())+" is configured")
+	}
+
+	// Walk an image change through its backup, migrations and rollout
+	upgradeInFlight, err := r.reconcileUpgrade(ctx, ghost)
+	if err != nil {
+		log.Error(err, "Failed to upgrade Ghost")
+		return ctrl.Result{}, err
+	}
+
+	// Keep the Ghost scaled down while a restore replaces its data
+	if err := r.updateRestoreCondition(ctx, ghost); err != nil {
+		log.Error(err, "Failed to check the restores of Ghost")
+		return ctrl.Result{}, err
+	}
+
+	// Hold the Ghost at one replica when its pods could not share their data
+	if err := r.updateScalingCondition(ctx, ghost); err != nil {
+		log.Error(err, "Failed to check the scaling of Ghost")
+		return ctrl.Result{}, err
 	}
 
 	// Add or update Deployment
-	if err := r.addOrUpdateDeployment(ctx, ghost); err != nil {
+	deployment, err := r.addOrUpdateDeployment(ctx, ghost)
+	if err != nil {
 		log.Error(err, "Failed to add or update Deployment for Ghost")
-		addCondition(&ghost.Status, "DeploymentNotReady", metav1.ConditionFalse, "DeploymentNotReady", "Failed to add or update Deployment for Ghost")
+		addCondition(ghost, "DeploymentNotReady", metav1.ConditionFalse, "DeploymentNotReady", "Failed to add or update Deployment for Ghost")
+		return ctrl.Result{}, err
+	}
+	deploymentReady = true
+	meta.RemoveStatusCondition(&ghost.Status.Conditions, "DeploymentNotReady")
+
+	// Report the rollout state of the Deployment, its replicas and the image it runs
+	rolloutInFlight := updateRolloutConditions(ghost, deployment)
+	updateScaleStatus(ghost, deployment)
+	if err := r.updateResolvedImage(ctx, ghost, deployment); err != nil {
+		log.Error(err, "Failed to resolve the image of Ghost")
 		return ctrl.Result{}, err
-	} else {
-		deploymentReady = true
 	}
 
+	// Add, update or remove the HorizontalPodAutoscaler
+	if err := r.addOrUpdateHPA(ctx, ghost, deployment.Name); err != nil {
+		log.Error(err, "Failed to add or update HorizontalPodAutoscaler for Ghost")
+		addCondition(ghost, "HPANotReady", metav1.ConditionFalse, "HPANotReady", "Failed to add or update HorizontalPodAutoscaler for Ghost")
+		return ctrl.Result{}, err
+	}
+	meta.RemoveStatusCondition(&ghost.Status.Conditions, "HPANotReady")
+
 	// Add or update Service
-	if err := r.addServiceIfNotExists(ctx, ghost); err != nil {
-		log.Error(err, "Failed to add Service for Ghost")
-		addCondition(&ghost.Status, "ServiceNotReady", metav1.ConditionFalse, "ServiceNotReady", "Failed to add Service for Ghost")
+	if err := r.addOrUpdateService(ctx, ghost); err != nil {
+		log.Error(err, "Failed to add or update Service for Ghost")
+		addCondition(ghost, "ServiceNotReady", metav1.ConditionFalse, "ServiceNotReady", "Failed to add or update Service for Ghost")
 		return ctrl.Result{}, err
-	} else {
-		serviceReady = true
 	}
+	serviceReady = true
+	meta.RemoveStatusCondition(&ghost.Status.Conditions, "ServiceNotReady")
 
-	// Check if all subresources are ready
-	if pvcReady && deploymentReady && serviceReady {
-		// Add your desired condition when all subresources are ready
-		addCondition(&ghost.Status, "GhostReady", metav1.ConditionTrue, "AllSubresourcesReady", "All subresources are ready")
+	// Add, update or remove the Ingress
+	if err := r.addOrUpdateIngress(ctx, ghost); err != nil {
+		log.Error(err, "Failed to add or update Ingress for Ghost")
+		addCondition(ghost, "IngressNotReady", metav1.ConditionFalse, "IngressNotReady", "Failed to add or update Ingress for Ghost")
+		return ctrl.Result{}, err
+	}
+	meta.RemoveStatusCondition(&ghost.Status.Conditions, "IngressNotReady")
+
+	// Add, update or remove the HTTPRoute
+	if err := r.addOrUpdateHTTPRoute(ctx, ghost); err != nil {
+		log.Error(err, "Failed to add or update HTTPRoute for Ghost")
+		addCondition(ghost, "HTTPRouteNotReady", metav1.ConditionFalse, "HTTPRouteNotReady", "Failed to add or update HTTPRoute for Ghost")
+		return ctrl.Result{}, err
+	}
+	meta.RemoveStatusCondition(&ghost.Status.Conditions, "HTTPRouteNotReady")
+
+	// Take the scheduled backups and prune the expired ones
+	nextBackup, err := r.reconcileBackupSchedule(ctx, ghost)
+	if err != nil {
+		log.Error(err, "Failed to reconcile the backup schedule of Ghost")
+		return ctrl.Result{}, err
+	}
+
+	// Check if all subresources are ready and the pods serve the current spec
+	switch {
+	case !(pvcReady && deploymentReady && serviceReady):
+		addCondition(ghost, "GhostReady", metav1.ConditionFalse, "SubresourcesNotReady", "Not all subresources are ready")
+	case !meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionAvailable):
+		addCondition(ghost, "GhostReady", metav1.ConditionFalse, "DeploymentUnavailable", "Ghost pods are not available")
+	case rolloutInFlight:
+		addCondition(ghost, "GhostReady", metav1.ConditionFalse, "RolloutInProgress", "Ghost pods are being rolled out")
+	default:
+		addCondition(ghost, "GhostReady", metav1.ConditionTrue, "AllSubresourcesReady", "All subresources are ready")
 	}
+	ghost.Status.ObservedGeneration = ghost.Generation
 
 	log.Info("Reconciliation complete")
 
@@ -126,44 +292,71 @@ func (r *GhostReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, err
 	}
 
+	// Check back until the managed database is up, the rollout and upgrade are done and the PVC is resized
+	if !databaseReady || rolloutInFlight || storageResizing || upgradeInFlight {
+		return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+	}
+
+	// Wake up for the next scheduled backup
+	if nextBackup > 0 {
+		return ctrl.Result{RequeueAfter: nextBackup}, nil
+	}
+
 	return ctrl.Result{}, nil
 
 }
-func (r *GhostReconciler) addPvcIfNotExists(ctx context.Context, ghost *blogv1.Ghost) error {
+func (r *GhostReconciler) addOrUpdatePvc(ctx context.Context, ghost *blogv2.Ghost) (bool, error) {
 	log := log.FromContext(ctx)
 
 	pvc := &corev1.PersistentVolumeClaim{}
-	team := ghost.ObjectMeta.Namespace
-	pvcName := pvcNamePrefix + team
+	pvcName, err := r.dataClaimName(ctx, ghost)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: pvcName}, pvc)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return false, err
+	}
 
-	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: pvcName}, pvc)
+	if ghost.Spec.Storage.ExistingClaim != "" {
+		// The claim is provided by the user, it only has to exist
+		if err != nil {
+			return false, fmt.Errorf("PVC %s referenced by spec.storage.existingClaim not found", pvcName)
+		}
+		meta.RemoveStatusCondition(&ghost.Status.Conditions, conditionStorageResizing)
+		return false, nil
+	}
 
 	if err == nil {
-		// PVC exists, we are done here!
-		return nil
+		// PVC exists, make sure it is ours before using it
+		if !metav1.IsControlledBy(pvc, ghost) {
+			return false, fmt.Errorf("PVC %s already exists and is not owned by Ghost %s", pvcName, ghost.Name)
+		}
+		return r.resizePvc(ctx, ghost, pvc)
 	}
 
 	// PVC does not exist, create it
-	//desiredPVC := generateDesiredPVC(ghost, pvcName)
 	desiredPVC, err := createDesiredPVC(ghost, pvcName)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	// Update owner reference
 	if err := controllerutil.SetControllerReference(ghost, desiredPVC, r.Scheme); err != nil {
-		return err
+		return false, err
 	}
 
 	if err := r.Create(ctx, desiredPVC); err != nil {
-		return err
+		return false, err
 	}
 	r.recoder.Event(ghost, corev1.EventTypeNormal, "PVCReady", "PVC created successfully")
 	log.Info("PVC created", "pvc", pvcName)
-	return nil
+	meta.RemoveStatusCondition(&ghost.Status.Conditions, conditionStorageResizing)
+	return false, nil
 }
 
-func createDesiredPVC(ghost *blogv1.Ghost, pvcName string) (*corev1.PersistentVolumeClaim, error) {
+func createDesiredPVC(ghost *blogv2.Ghost, pvcName string) (*corev1.PersistentVolumeClaim, error) {
 	pvcData, err := assets.GetPersistentVolumeClaimFromFile("manifests/ghost_data_pvc.yaml")
 	if err != nil {
 		return nil, err
@@ -172,192 +365,446 @@ func createDesiredPVC(ghost *blogv1.Ghost, pvcName string) (*corev1.PersistentVo
 	// initialize the object
 	pvcData.Name = pvcName
 	pvcData.Namespace = ghost.ObjectMeta.Namespace
+	pvcData.Labels = labelsForGhost(ghost)
+
+	// apply the storage settings of the Ghost
+	storage := ghost.Spec.Storage
+	pvcData.Spec.Resources.Requests[corev1.ResourceStorage] = ghost.Spec.StorageSize()
+	if storage.StorageClassName != nil {
+		pvcData.Spec.StorageClassName = storage.StorageClassName
+	}
+	pvcData.Spec.AccessModes = ghost.Spec.StorageAccessModes()
+	pvcData.Spec.Selector = storage.Selector
 
 	return pvcData, nil
 }
 
-func createDesiredDeployment(ghost *blogv1.Ghost) (*appsv1.Deployment, error) {
+func createDesiredDeployment(ghost *blogv2.Ghost, pvcName string, replicas *int32) (*appsv1.Deployment, error) {
 	deploy, err := assets.GetDeploymentFromFile("manifests/ghost_deployment.yaml")
 	if err != nil {
 		return nil, err
 	}
 
 	// initialize the object
-	replicas := int32(1)
-	deploy.ObjectMeta.GenerateName = deploymentNamePrefix
+	deploy.ObjectMeta.Name = deploymentNamePrefix + ghost.ObjectMeta.Name
 	deploy.ObjectMeta.Namespace = ghost.ObjectMeta.Namespace
-	deploy.ObjectMeta.Labels["app"] = "ghost-" + ghost.ObjectMeta.Namespace
-	deploy.Spec.Replicas = &replicas
-	deploy.Spec.Selector.MatchLabels["app"] = "ghost-" + ghost.ObjectMeta.Namespace
-	deploy.Spec.Template.Labels["app"] = "ghost-" + ghost.ObjectMeta.Namespace
-	deploy.Spec.Template.Spec.Containers[0].Image = "ghost:" + ghost.Spec.ImageTag
-	deploy.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName = "ghost-data-pvc-" + ghost.ObjectMeta.Namespace
-
-	return deploy, err
+	deploy.ObjectMeta.Labels = labelsForGhost(ghost)
+	deploy.Spec.Replicas = replicas
+	deploy.Spec.Strategy = deploymentStrategy(ghost)
+	deploy.Spec.Selector.MatchLabels = selectorForGhost(ghost)
+	deploy.Spec.Template.Labels = labelsForGhost(ghost)
+	deploy.Spec.Template.Spec.Containers[0].Image = deploymentImage(ghost)
+	deploy.Spec.Template.Spec.Containers[0].ImagePullPolicy = ghost.Spec.Image.PullPolicy
+	deploy.Spec.Template.Spec.ImagePullSecrets = ghost.Spec.Image.ImagePullSecrets
+	deploy.Spec.Template.Spec.Containers[0].Resources = containerResources(ghost)
+	startupProbe, livenessProbe, readinessProbe := containerProbes(ghost)
+	deploy.Spec.Template.Spec.Containers[0].StartupProbe = startupProbe
+	deploy.Spec.Template.Spec.Containers[0].LivenessProbe = livenessProbe
+	deploy.Spec.Template.Spec.Containers[0].ReadinessProbe = readinessProbe
+	deploy.Spec.Template.Spec.Containers[0].Env = withConfigEnv(ghost, withURLEnv(ghost, withDatabaseEnv(ghost, withEnvironmentEnv(ghost, deploy.Spec.Template.Spec.Containers[0].Env))))
+	deploy.Spec.Template.Spec.Containers[0].EnvFrom = ghost.Spec.Config.EnvFrom
+	deploy.Spec.Template.Spec.Containers[0].SecurityContext = containerSecurityContext(ghost)
+	deploy.Spec.Template.Spec.SecurityContext = podSecurityContext(ghost)
+	applyPodPlacement(ghost, &deploy.Spec.Template.Spec)
+	deploy.Spec.Template.Spec.InitContainers = databaseInitContainers(ghost)
+	applySQLiteReplication(ghost, &deploy.Spec.Template.Spec)
+	deploy.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName = pvcName
+
+	hash, err := specHash(deploy.Spec)
+	if err != nil {
+		return nil, err
+	}
+	deploy.ObjectMeta.Annotations = map[string]string{specHashAnnotation: hash}
+
+	return deploy, nil
 }
 
-func createDesiredService(ghost *blogv1.Ghost) (*corev1.Service, error) {
+func createDesiredService(ghost *blogv2.Ghost) (*corev1.Service, error) {
 	service, err := assets.GetServiceFromFile("manifests/ghost_service.yaml")
 	if err != nil {
 		return nil, err
 	}
 
 	// initialize the object
-	service.Name = "ghost-service-" + ghost.ObjectMeta.Namespace
+	service.Name = svcNamePrefix + ghost.ObjectMeta.Name
 	service.Namespace = ghost.ObjectMeta.Namespace
-	service.Spec.Selector["app"] = "ghost-" + ghost.ObjectMeta.Namespace
+	service.Labels = labelsForGhost(ghost)
+	syncAnnotations(service, ghost.Spec.Service.Annotations)
+	service.Spec.Type = ghost.Spec.ServiceType()
+	service.Spec.Ports[0].Port = ghost.Spec.ServicePort()
+	service.Spec.Ports[0].NodePort = ghost.Spec.ServiceNodePort()
+	service.Spec.Selector = selectorForGhost(ghost)
 
 	return service, nil
 }
 
-func generateDesiredPVC(ghost *blogv1.Ghost, pvcName string) *corev1.PersistentVolumeClaim {
-	return &corev1.PersistentVolumeClaim{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      pvcName,
-			Namespace: ghost.ObjectMeta.Namespace,
-		},
-		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-			Resources: corev1.VolumeResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: resource.MustParse("1Gi"),
-				},
-			},
-		},
+func (r *GhostReconciler) addOrUpdateDeployment(ctx context.Context, ghost *blogv2.Ghost) (*appsv1.Deployment, error) {
+	log := log.FromContext(ctx)
+
+	pvcName, err := r.dataClaimName(ctx, ghost)
+	if err != nil {
+		return nil, err
+	}
+
+	deployments, err := r.listGhostDeployments(ctx, ghost)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas, err := r.deploymentReplicas(ctx, ghost)
+	if err != nil {
+		return nil, err
+	}
+
+	desiredDeployment, err := createDesiredDeployment(ghost, pvcName, replicas)
+	if err != nil {
+		return nil, err
 	}
+
+	existingDeployment, err := r.pruneDuplicateDeployments(ctx, ghost, deployments)
+	if err != nil {
+		return nil, err
+	}
+	if existingDeployment != nil && existingDeployment.Name != desiredDeployment.Name && existingDeployment.Name != ghost.Status.DeploymentName {
+		// A Deployment created with a generated name or the legacy selector by
+		// an older operator version
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "DeploymentAdopted", "Deployment "+existingDeployment.Name+" adopted")
+		log.Info("Deployment adopted", "deployment", existingDeployment.Name)
+	}
+
+	if existingDeployment != nil {
+		if replicas == nil && existingDeployment.Spec.Replicas != nil && *existingDeployment.Spec.Replicas == 0 {
+			// The autoscaler does not scale up from zero, start it over after a restore
+			desiredDeployment.Spec.Replicas = minReplicas(ghost)
+		}
+
+		if desiredDeployment.Spec.Replicas == nil {
+			// Keep the replicas the autoscaler settled on
+			desiredDeployment.Spec.Replicas = existingDeployment.Spec.Replicas
+		}
+
+		if existingDeployment.Name != desiredDeployment.Name {
+			return r.replaceAdoptedDeployment(ctx, ghost, desiredDeployment, existingDeployment)
+		}
+
+		// Deployment exists, bring everything the Ghost declares back in line
+		if err := r.updateDeployment(ctx, ghost, desiredDeployment, existingDeployment); err != nil {
+			return nil, err
+		}
+		ghost.Status.DeploymentName = existingDeployment.Name
+		return existingDeployment, nil
+	}
+
+	// Deployment does not exist, create it
+	if err := controllerutil.SetControllerReference(ghost, desiredDeployment, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := r.Create(ctx, desiredDeployment); err != nil {
+		return nil, err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "DeploymentCreated", "Deployment created successfully")
+	log.Info("Deployment created", "deployment", desiredDeployment.Name, "team", ghost.ObjectMeta.Namespace)
+	ghost.Status.DeploymentName = desiredDeployment.Name
+	return desiredDeployment, nil
 }
 
-func (r *GhostReconciler) addOrUpdateDeployment(ctx context.Context, ghost *blogv1.Ghost) error {
+// updateDeployment brings the existing Deployment back in line with the
+// desired one when it drifted.
+func (r *GhostReconciler) updateDeployment(ctx context.Context, ghost *blogv2.Ghost, desired, existing *appsv1.Deployment) error {
 	log := log.FromContext(ctx)
-	deploymentList := &appsv1.DeploymentList{}
-	labelSelector := labels.Set{"app": "ghost-" + ghost.ObjectMeta.Namespace}
 
-	err := r.List(ctx, deploymentList, &client.ListOptions{
-		Namespace:     ghost.ObjectMeta.Namespace,
-		LabelSelector: labelSelector.AsSelector(),
-	})
+	needsUpdate, err := r.deploymentNeedsUpdate(ctx, desired, existing)
 	if err != nil {
 		return err
 	}
+	if !needsUpdate {
+		log.Info("Deployment is up to date, no action required", "deployment", existing.Name)
+		return nil
+	}
+	existing.Spec = desired.Spec
+	existing.Labels = mergeLabels(existing.Labels, desired.Labels)
+	mergeAnnotations(existing, desired.Annotations)
+	if err := r.Update(ctx, existing); err != nil {
+		return err
+	}
+	log.Info("Deployment updated", "deployment", existing.Name)
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "DeploymentUpdated", "Deployment updated successfully")
+	return nil
+}
 
-	if len(deploymentList.Items) > 0 {
-		// Deployment exists, update it
-		existingDeployment := &deploymentList.Items[0] // Assuming only one deployment exists
-		desiredDeployment := generateDesiredDeployment(ghost)
+// replaceAdoptedDeployment moves the Ghost from an adopted Deployment with a
+// generated name to one with the stable name. When the pods of both may run
+// at once, the adopted Deployment keeps serving until the stable one is
+// available. Otherwise it is scaled down and deleted before the stable one is
+// created, so SQLite and a ReadWriteOnce volume are never opened twice. It
+// returns the Deployment currently serving the Ghost.
+func (r *GhostReconciler) replaceAdoptedDeployment(ctx context.Context, ghost *blogv2.Ghost, desired, adopted *appsv1.Deployment) (*appsv1.Deployment, error) {
+	log := log.FromContext(ctx)
 
-		// Compare relevant fields to determine if an update is needed
-		if existingDeployment.Spec.Template.Spec.Containers[0].Image != desiredDeployment.Spec.Template.Spec.Containers[0].Image {
-			// Fields have changed, update the deployment
-			existingDeployment.Spec = desiredDeployment.Spec
-			if err := r.Update(ctx, existingDeployment); err != nil {
-				return err
-			}
-			log.Info("Deployment updated", "deployment", existingDeployment.Name)
-			r.recoder.Event(ghost, corev1.EventTypeNormal, "DeploymentUpdated", "Deployment updated successfully")
-		} else {
-			log.Info("Deployment is up to date, no action required", "deployment", existingDeployment.Name)
+	overlap, err := r.podsMayOverlap(ctx, ghost)
+	if err != nil {
+		return nil, err
+	}
+	if !overlap {
+		return r.recreateAdoptedDeployment(ctx, ghost, desired, adopted)
+	}
+
+	ghost.Status.DeploymentName = adopted.Name
+	// A legacy Deployment cannot take the selector of the Ghost and is left as
+	// it is until replaced
+	if equality.Semantic.DeepEqual(adopted.Spec.Selector, desired.Spec.Selector) {
+		if err := r.updateDeployment(ctx, ghost, desired.DeepCopy(), adopted); err != nil {
+			return nil, err
 		}
-		return nil
 	}
 
-	// Deployment does not exist, create it
-	//desiredDeployment := generateDesiredDeployment(ghost)
-	desiredDeployment, err := createDesiredDeployment(ghost)
+	stable := &appsv1.Deployment{}
+	err = r.Get(ctx, client.ObjectKey{Namespace: desired.Namespace, Name: desired.Name}, stable)
+	if errors.IsNotFound(err) {
+		if err := r.createReplacingDeployment(ctx, ghost, desired, adopted); err != nil {
+			return nil, err
+		}
+		return adopted, nil
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if err := controllerutil.SetControllerReference(ghost, desiredDeployment, r.Scheme); err != nil {
+	if !metav1.IsControlledBy(stable, ghost) {
+		return nil, fmt.Errorf("deployment %s exists and is not managed by Ghost %s", stable.Name, ghost.Name)
+	}
+
+	if err := r.updateDeployment(ctx, ghost, desired, stable); err != nil {
+		return nil, err
+	}
+	if !deploymentRolledOut(stable) {
+		log.Info("Waiting for the Deployment replacing the adopted one", "deployment", stable.Name, "adopted", adopted.Name)
+		return adopted, nil
+	}
+
+	if err := r.Delete(ctx, adopted); client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "AdoptedDeploymentReplaced", "Deployment "+adopted.Name+" replaced by "+stable.Name)
+	log.Info("Adopted Deployment replaced", "deployment", stable.Name, "adopted", adopted.Name)
+	ghost.Status.DeploymentName = stable.Name
+	return stable, nil
+}
+
+// recreateAdoptedDeployment scales the adopted Deployment down, deletes it
+// once its pods are gone and creates the one with the stable name in its
+// place, like the Recreate strategy does for a single Deployment.
+func (r *GhostReconciler) recreateAdoptedDeployment(ctx context.Context, ghost *blogv2.Ghost, desired, adopted *appsv1.Deployment) (*appsv1.Deployment, error) {
+	log := log.FromContext(ctx)
+
+	ghost.Status.DeploymentName = adopted.Name
+	if adopted.Spec.Replicas == nil || *adopted.Spec.Replicas != 0 {
+		zero := int32(0)
+		adopted.Spec.Replicas = &zero
+		if err := r.Update(ctx, adopted); err != nil {
+			return nil, err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "AdoptedDeploymentScaledDown",
+			"Deployment "+adopted.Name+" scaled down to be replaced by "+desired.Name)
+		log.Info("Adopted Deployment scaled down", "deployment", desired.Name, "adopted", adopted.Name)
+		return adopted, nil
+	}
+	if adopted.Status.Replicas > 0 {
+		log.Info("Waiting for the pods of the adopted Deployment to stop", "adopted", adopted.Name)
+		return adopted, nil
+	}
+
+	if err := r.Delete(ctx, adopted); client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	if err := r.createReplacingDeployment(ctx, ghost, desired, adopted); client.IgnoreAlreadyExists(err) != nil {
+		return nil, err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "AdoptedDeploymentReplaced", "Deployment "+adopted.Name+" replaced by "+desired.Name)
+	ghost.Status.DeploymentName = desired.Name
+	return desired, nil
+}
+
+// createReplacingDeployment creates the Deployment with the stable name that
+// takes over from the adopted one
+func (r *GhostReconciler) createReplacingDeployment(ctx context.Context, ghost *blogv2.Ghost, desired, adopted *appsv1.Deployment) error {
+	if err := controllerutil.SetControllerReference(ghost, desired, r.Scheme); err != nil {
 		return err
 	}
-	if err := r.Create(ctx, desiredDeployment); err != nil {
+	if err := r.Create(ctx, desired); err != nil {
 		return err
 	}
-	r.recoder.Event(ghost, corev1.EventTypeNormal, "DeploymentCreated", "Deployment created successfully")
-	log.Info("Deployment created", "team", ghost.ObjectMeta.Namespace)
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "DeploymentCreated", "Deployment "+desired.Name+" created to replace "+adopted.Name)
+	log.FromContext(ctx).Info("Deployment created to replace the adopted one", "deployment", desired.Name, "adopted", adopted.Name)
 	return nil
 }
 
-func generateDesiredDeployment(ghost *blogv1.Ghost) *appsv1.Deployment {
-	replicas := int32(1) // Adjust replica count as needed
-	return &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			GenerateName: deploymentNamePrefix,
-			Namespace:    ghost.ObjectMeta.Namespace,
-			Labels: map[string]string{
-				"app": "ghost-" + ghost.ObjectMeta.Namespace,
-			},
-		},
-		Spec: appsv1.DeploymentSpec{
-			Replicas: &replicas,
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"app": "ghost-" + ghost.ObjectMeta.Namespace,
-				},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": "ghost-" + ghost.ObjectMeta.Namespace,
-					},
-				},
-				Spec: corev1.PodSpec{
-					Containers: []corev1.Container{
-						{
-							Name:  "ghost",
-							Image: "ghost:" + ghost.Spec.ImageTag,
-							Env: []corev1.EnvVar{
-								{
-									Name:  "NODE_ENV",
-									Value: "development",
-								},
-								{
-									Name:  "database__connection__filename",
-									Value: "/var/lib/ghost/content/data/ghost.db",
-								},
-							},
-							Ports: []corev1.ContainerPort{
-								{
-									ContainerPort: 2368,
-								},
-							},
-							VolumeMounts: []corev1.VolumeMount{
-								{
-									Name:      "ghost-data",
-									MountPath: "/var/lib/ghost/content",
-								},
-							},
-						},
-					},
-					Volumes: []corev1.Volume{
-						{
-							Name: "ghost-data",
-							VolumeSource: corev1.VolumeSource{
-								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-									ClaimName: "ghost-data-pvc-" + ghost.ObjectMeta.Namespace,
-								},
-							},
-						},
-					},
-				},
-			},
-		},
+// pruneDuplicateDeployments picks the Deployment managed for the Ghost out of
+// the ones selecting its pods, and deletes any other Deployment the Ghost
+// controls. The one recorded in the status wins, then the one with the stable
+// name. Otherwise the oldest one is kept, since it is the one serving traffic;
+// this adopts Deployments created with a generated name by older versions.
+// Deployments not controlled by the Ghost are left alone, and so is the one
+// with the stable name, which takes over from an adopted one.
+func (r *GhostReconciler) pruneDuplicateDeployments(ctx context.Context, ghost *blogv2.Ghost, deployments []appsv1.Deployment) (*appsv1.Deployment, error) {
+	log := log.FromContext(ctx)
+
+	var owned []*appsv1.Deployment
+	for i := range deployments {
+		if metav1.IsControlledBy(&deployments[i], ghost) && deployments[i].DeletionTimestamp.IsZero() {
+			owned = append(owned, &deployments[i])
+		}
+	}
+	if len(owned) == 0 {
+		return nil, nil
+	}
+
+	rank := func(d *appsv1.Deployment) int {
+		switch d.Name {
+		case ghost.Status.DeploymentName:
+			return 0
+		case deploymentNamePrefix + ghost.ObjectMeta.Name:
+			return 1
+		}
+		return 2
 	}
+	sort.Slice(owned, func(i, j int) bool {
+		if rank(owned[i]) != rank(owned[j]) {
+			return rank(owned[i]) < rank(owned[j])
+		}
+		if owned[i].CreationTimestamp.Equal(&owned[j].CreationTimestamp) {
+			return owned[i].Name < owned[j].Name
+		}
+		return owned[i].CreationTimestamp.Before(&owned[j].CreationTimestamp)
+	})
+
+	for _, duplicate := range owned[1:] {
+		if duplicate.Name == deploymentNamePrefix+ghost.ObjectMeta.Name {
+			// Replacing the adopted Deployment, see replaceAdoptedDeployment
+			continue
+		}
+		if err := r.Delete(ctx, duplicate); client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeWarning, "DuplicateDeploymentDeleted", "Duplicate Deployment "+duplicate.Name+" deleted")
+		log.Info("Duplicate Deployment deleted", "deployment", duplicate.Name)
+	}
+	return owned[0], nil
 }
 
-func (r *GhostReconciler) addServiceIfNotExists(ctx context.Context, ghost *blogv1.Ghost) error {
+// deploymentNeedsUpdate reports whether the existing Deployment drifted from
+// the desired one. The desired state is written in a dry run first, so the
+// API server fills in its defaults and the whole spec can be compared with
+// the existing one, catching fields and list items added by hand as well.
+func (r *GhostReconciler) deploymentNeedsUpdate(ctx context.Context, desired, existing *appsv1.Deployment) (bool, error) {
+	if existing.Annotations[specHashAnnotation] != desired.Annotations[specHashAnnotation] {
+		return true, nil
+	}
+	for k, v := range desired.Labels {
+		if existing.Labels[k] != v {
+			return true, nil
+		}
+	}
+
+	defaulted := existing.DeepCopy()
+	defaulted.Spec = *desired.Spec.DeepCopy()
+	if err := r.Update(ctx, defaulted, client.DryRunAll); err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepEqual(defaulted.Spec, existing.Spec), nil
+}
+
+// specHash returns a short hash of the given spec, stored on the objects built
+// from it to notice when the desired state changes.
+func specHash(spec interface{}) (string, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// mergeLabels returns current with the given labels set on it.
+func mergeLabels(current, labels map[string]string) map[string]string {
+	if current == nil {
+		current = map[string]string{}
+	}
+	for k, v := range labels {
+		current[k] = v
+	}
+	return current
+}
+
+// syncAnnotations sets the given annotations on obj and removes the ones set
+// from an earlier declaration that are no longer given. Annotations added by
+// others are left alone. It reports whether anything changed.
+func syncAnnotations(obj metav1.Object, annotations map[string]string) bool {
+	current := obj.GetAnnotations()
+	if current == nil {
+		current = map[string]string{}
+	}
+	changed := false
+	for _, k := range strings.Split(current[managedAnnotationsAnnotation], ",") {
+		if _, declared := annotations[k]; declared || k == "" {
+			continue
+		}
+		if _, ok := current[k]; ok {
+			delete(current, k)
+			changed = true
+		}
+	}
+
+	keys := make([]string, 0, len(annotations))
+	for k, v := range annotations {
+		keys = append(keys, k)
+		if current[k] != v {
+			current[k] = v
+			changed = true
+		}
+	}
+	sort.Strings(keys)
+	if managed := strings.Join(keys, ","); current[managedAnnotationsAnnotation] != managed {
+		if managed == "" {
+			delete(current, managedAnnotationsAnnotation)
+		} else {
+			current[managedAnnotationsAnnotation] = managed
+		}
+		changed = true
+	}
+
+	if changed {
+		obj.SetAnnotations(current)
+	}
+	return changed
+}
+
+func (r *GhostReconciler) addOrUpdateService(ctx context.Context, ghost *blogv2.Ghost) error {
 	log := log.FromContext(ctx)
 	service := &corev1.Service{}
-	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: svcNamePrefix + ghost.ObjectMeta.Namespace}, service)
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: svcNamePrefix + ghost.ObjectMeta.Name}, service)
 	if err != nil && client.IgnoreNotFound(err) != nil {
 		return err
 	}
 
 	if err == nil {
-		// Service exists
+		// Service exists, make sure it is ours and update it
+		if !metav1.IsControlledBy(service, ghost) {
+			return fmt.Errorf("service %s already exists and is not owned by Ghost %s", service.Name, ghost.Name)
+		}
+
+		if updateServiceFields(ghost, service) {
+			if err := r.Update(ctx, service); err != nil {
+				return err
+			}
+			log.Info("Service updated", "service", service.Name)
+			r.recoder.Event(ghost, corev1.EventTypeNormal, "ServiceUpdated", "Service updated successfully")
+		} else {
+			log.Info("Service is up to date, no action required", "service", service.Name)
+		}
+		ghost.Status.NodePort = service.Spec.Ports[0].NodePort
 		return nil
 	}
 	// Service does not exist, create it
-	//desiredService := generateDesiredService(ghost)
 	desiredService, err := createDesiredService(ghost)
 	if err != nil {
 		return err
@@ -373,57 +820,81 @@ func (r *GhostReconciler) addServiceIfNotExists(ctx context.Context, ghost *blog
 	}
 	r.recoder.Event(ghost, corev1.EventTypeNormal, "ServiceCreated", "Service created successfully")
 	log.Info("Service created", "service", desiredService.Name)
+
+	// The cluster fills in the allocated node port on create
+	ghost.Status.NodePort = desiredService.Spec.Ports[0].NodePort
 	return nil
 }
 
-func generateDesiredService(ghost *blogv1.Ghost) *corev1.Service {
-	return &corev1.Service{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "ghost-service-" + ghost.ObjectMeta.Namespace,
-			Namespace: ghost.ObjectMeta.Namespace,
-		},
-		Spec: corev1.ServiceSpec{
-			Type: corev1.ServiceTypeNodePort,
-			Ports: []corev1.ServicePort{
-				{
-					Port:       80,
-					TargetPort: intstr.FromInt32(2368),
-					NodePort:   30001,
-				},
-			},
-			Selector: map[string]string{
-				"app": "ghost-" + ghost.ObjectMeta.Namespace,
-			},
-		},
+// updateServiceFields brings the fields of the Service managed through
+// spec.service in line with the Ghost and reports whether anything changed.
+// An allocated node port is kept unless the Ghost asks for a specific one.
+func updateServiceFields(ghost *blogv2.Ghost, service *corev1.Service) bool {
+	changed := syncAnnotations(service, ghost.Spec.Service.Annotations)
+
+	if service.Spec.Type != ghost.Spec.ServiceType() {
+		service.Spec.Type = ghost.Spec.ServiceType()
+		changed = true
+	}
+
+	if !reflect.DeepEqual(service.Spec.Selector, selectorForGhost(ghost)) {
+		service.Spec.Selector = selectorForGhost(ghost)
+		changed = true
+	}
+
+	if len(service.Spec.Ports) != 1 {
+		service.Spec.Ports = []corev1.ServicePort{{TargetPort: intstr.FromInt32(2368)}}
+		changed = true
+	}
+	port := &service.Spec.Ports[0]
+	if port.Port != ghost.Spec.ServicePort() {
+		port.Port = ghost.Spec.ServicePort()
+		changed = true
+	}
+
+	nodePort := ghost.Spec.ServiceNodePort()
+	switch {
+	case service.Spec.Type == corev1.ServiceTypeClusterIP && port.NodePort != 0:
+		// ClusterIP Services must not carry a node port
+		port.NodePort = 0
+		changed = true
+	case nodePort != 0 && port.NodePort != nodePort:
+		port.NodePort = nodePort
+		changed = true
 	}
+
+	return changed
 }
 
-// Function to add a condition to the GhostStatus
-func addCondition(status *blogv1.GhostStatus, condType string, statusType metav1.ConditionStatus, reason, message string) {
-	for i, existingCondition := range status.Conditions {
-		if existingCondition.Type == condType {
-			// Condition already exists, update it
-			status.Conditions[i].Status = statusType
-			status.Conditions[i].Reason = reason
-			status.Conditions[i].Message = message
-			status.Conditions[i].LastTransitionTime = metav1.Now()
-			return
-		}
+// labelsForGhost returns the labels put on every object owned by the Ghost.
+func labelsForGhost(ghost *blogv2.Ghost) map[string]string {
+	labels := selectorForGhost(ghost)
+	labels[managedByLabel] = "ghost-operator"
+	return labels
+}
+
+// selectorForGhost returns the labels that uniquely select the pods of the Ghost.
+func selectorForGhost(ghost *blogv2.Ghost) map[string]string {
+	return map[string]string{
+		nameLabel:     "ghost",
+		instanceLabel: ghost.ObjectMeta.Name,
 	}
+}
 
-	// Condition does not exist, add it
-	condition := metav1.Condition{
+// Function to add or update a condition in the GhostStatus. The condition
+// records the generation of the Ghost it was computed for.
+func addCondition(ghost *blogv2.Ghost, condType string, statusType metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&ghost.Status.Conditions, metav1.Condition{
 		Type:               condType,
 		Status:             statusType,
 		Reason:             reason,
 		Message:            message,
-		LastTransitionTime: metav1.Now(),
-	}
-	status.Conditions = append(status.Conditions, condition)
+		ObservedGeneration: ghost.Generation,
+	})
 }
 
 // Function to update the status of the Ghost object
-func (r *GhostReconciler) updateStatus(ctx context.Context, ghost *blogv1.Ghost) error {
+func (r *GhostReconciler) updateStatus(ctx context.Context, ghost *blogv2.Ghost) error {
 	// Update the status of the Ghost object
 	if err := r.Status().Update(ctx, ghost); err != nil {
 		return err
@@ -433,9 +904,28 @@ func (r *GhostReconciler) updateStatus(ctx context.Context, ghost *blogv1.Ghost)
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Changes to the owned children bring the Ghost back into reconciliation, so
+// drift is corrected and rollout progress shows up in the Ghost status.
 func (r *GhostReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	r.recoder = mgr.GetEventRecorderFor("ghost-controller")
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&blogv1.Ghost{}).
-		Complete(r)
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&blogv2.Ghost{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))).
+		Owns(&appsv1.Deployment{}, builder.WithPredicates(rolloutChanged())).
+		Owns(&appsv1.StatefulSet{}, builder.WithPredicates(rolloutChanged())).
+		Owns(&corev1.Service{}, builder.WithPredicates(ignoreStatusChanges())).
+		Owns(&corev1.PersistentVolumeClaim{}, builder.WithPredicates(ignoreStatusChanges())).
+		Owns(&corev1.Secret{}, builder.WithPredicates(ignoreStatusChanges())).
+		Owns(&networkingv1.Ingress{}, builder.WithPredicates(ignoreStatusChanges())).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}, builder.WithPredicates(ignoreStatusChanges())).
+		Watches(&blogv2.GhostBackup{}, handler.EnqueueRequestsFromMapFunc(ghostForBackup)).
+		Watches(&blogv2.GhostRestore{}, handler.EnqueueRequestsFromMapFunc(ghostForRestore))
+
+	// HTTPRoutes can only be watched on clusters with the Gateway API installed
+	if _, err := mgr.GetRESTMapper().RESTMapping(gatewayv1.SchemeGroupVersion.WithKind("HTTPRoute").GroupKind(), gatewayv1.SchemeGroupVersion.Version); err == nil {
+		b = b.Owns(&gatewayv1.HTTPRoute{}, builder.WithPredicates(ignoreStatusChanges()))
+	} else if !meta.IsNoMatchError(err) {
+		return err
+	}
+
+	return b.Complete(r)
 }
@@ -18,16 +18,34 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	blogv1 "example.com/api/v1"
+	blogv2 "example.com/api/v2"
 )
 
 var _ = Describe("Ghost Controller", func() {
@@ -40,13 +58,13 @@ var _ = Describe("Ghost Controller", func() {
 			Name:      resourceName,
 			Namespace: "default", // TODO(user):Modify as needed
 		}
-		ghost := &blogv1.Ghost{}
+		ghost := &blogv2.Ghost{}
 
 		BeforeEach(func() {
 			By("creating the custom resource for the Kind Ghost")
 			err := k8sClient.Get(ctx, typeNamespacedName, ghost)
 			if err != nil && errors.IsNotFound(err) {
-				resource := &blogv1.Ghost{
+				resource := &blogv2.Ghost{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      resourceName,
 						Namespace: "default",
@@ -58,19 +76,15 @@ var _ = Describe("Ghost Controller", func() {
 		})
 
 		AfterEach(func() {
-			// TODO(user): Cleanup logic after each test, like removing the resource instance.
-			resource := &blogv1.Ghost{}
-			err := k8sClient.Get(ctx, typeNamespacedName, resource)
-			Expect(err).NotTo(HaveOccurred())
-
 			By("Cleanup the specific resource instance Ghost")
-			Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			deleteGhost(ctx, typeNamespacedName)
 		})
 		It("should successfully reconcile the resource", func() {
 			By("Reconciling the created resource")
 			controllerReconciler := &GhostReconciler{
-				Client: k8sClient,
-				Scheme: k8sClient.Scheme(),
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
 			}
 
 			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
@@ -81,4 +95,1977 @@ var _ = Describe("Ghost Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
-})
+
+	Context("When several Ghosts share a namespace", func() {
+		ctx := context.Background()
+		names := []string{"blog-one", "blog-two"}
+
+		BeforeEach(func() {
+			for _, name := range names {
+				resource := &blogv2.Ghost{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+				}
+				Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+			}
+		})
+
+		AfterEach(func() {
+			for _, name := range names {
+				deleteGhost(ctx, types.NamespacedName{Name: name, Namespace: "default"})
+			}
+		})
+
+		It("should give every Ghost its own children", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			for _, name := range names {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: name, Namespace: "default"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			for _, name := range names {
+				pvc := &corev1.PersistentVolumeClaim{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pvcNamePrefix + name, Namespace: "default"}, pvc)).To(Succeed())
+				Expect(pvc.Labels).To(HaveKeyWithValue(instanceLabel, name))
+
+				service := &corev1.Service{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: svcNamePrefix + name, Namespace: "default"}, service)).To(Succeed())
+				Expect(service.Spec.Selector).To(HaveKeyWithValue(instanceLabel, name))
+
+				deployments := &appsv1.DeploymentList{}
+				Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+					client.MatchingLabels{instanceLabel: name})).To(Succeed())
+				Expect(deployments.Items).To(HaveLen(1))
+				Expect(deployments.Items[0].Name).To(Equal(deploymentNamePrefix + name))
+				Expect(deployments.Items[0].Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).
+					To(Equal(pvcNamePrefix + name))
+
+				ghost := &blogv2.Ghost{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, ghost)).To(Succeed())
+				Expect(ghost.Status.DeploymentName).To(Equal(deploymentNamePrefix + name))
+			}
+		})
+	})
+
+	Context("When a Ghost was created by an older operator version", func() {
+		ctx := context.Background()
+
+		// createLegacyGhost creates a Ghost with the namespace named children
+		// an older operator version left for it
+		createLegacyGhost := func(name, namespace string) *blogv2.Ghost {
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}))).To(Succeed())
+			ghost := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, ghost)).To(Succeed())
+
+			legacyLabels := map[string]string{legacyAppLabel: "ghost-" + namespace}
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: pvcNamePrefix + namespace, Namespace: namespace, Labels: legacyLabels},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: svcNamePrefix + namespace, Namespace: namespace, Labels: legacyLabels},
+				Spec: corev1.ServiceSpec{
+					Type:     corev1.ServiceTypeNodePort,
+					Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(2368)}},
+					Selector: legacyLabels,
+				},
+			}
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: deploymentNamePrefix + "x7k2p", Namespace: namespace, Labels: legacyLabels},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: legacyLabels},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: legacyLabels},
+						Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "ghost", Image: "ghost:alpine"}}},
+					},
+				},
+			}
+			for _, obj := range []client.Object{pvc, service, deployment} {
+				Expect(controllerutil.SetControllerReference(ghost, obj, k8sClient.Scheme())).To(Succeed())
+				Expect(k8sClient.Create(ctx, obj)).To(Succeed())
+			}
+			return ghost
+		}
+
+		It("should keep the legacy PVC and replace the Service and Deployment", func() {
+			const namespace = "legacy-renamed"
+			ghost := createLegacyGhost("blog", namespace)
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(ghost)})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("relabelling the legacy PVC instead of creating one")
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: pvcNamePrefix + namespace, Namespace: namespace}, pvc)).To(Succeed())
+			Expect(pvc.Labels).To(HaveKeyWithValue(instanceLabel, "blog"))
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: pvcNamePrefix + "blog", Namespace: namespace}, pvc)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			By("replacing the legacy Service")
+			service := &corev1.Service{}
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: svcNamePrefix + namespace, Namespace: namespace}, service)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: svcNamePrefix + "blog", Namespace: namespace}, service)).To(Succeed())
+			Expect(service.Spec.Selector).To(Equal(selectorForGhost(ghost)))
+
+			By("scaling the legacy Deployment down instead of deleting it right away")
+			deployments := &appsv1.DeploymentList{}
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace(namespace))).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(deployments.Items[0].Name).To(Equal(deploymentNamePrefix + "x7k2p"))
+			Expect(*deployments.Items[0].Spec.Replicas).To(BeZero())
+
+			By("replacing the legacy Deployment once its pods are gone")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(ghost)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace(namespace))).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(deployments.Items[0].Name).To(Equal(deploymentNamePrefix + "blog"))
+			Expect(deployments.Items[0].Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(pvcNamePrefix + namespace))
+
+			deleteGhost(ctx, client.ObjectKeyFromObject(ghost))
+		})
+
+		It("should retarget the Service of a Ghost named after its namespace", func() {
+			const namespace = "legacy-same"
+			ghost := createLegacyGhost(namespace, namespace)
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+			serviceName := types.NamespacedName{Name: svcNamePrefix + namespace, Namespace: namespace}
+			legacyService := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, serviceName, legacyService)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(ghost)})
+			Expect(err).NotTo(HaveOccurred())
+
+			service := &corev1.Service{}
+			Expect(k8sClient.Get(ctx, serviceName, service)).To(Succeed())
+			Expect(service.UID).To(Equal(legacyService.UID))
+			Expect(service.Spec.Selector).To(Equal(selectorForGhost(ghost)))
+			Expect(service.Labels).NotTo(HaveKey(legacyAppLabel))
+
+			deployments := &appsv1.DeploymentList{}
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace(namespace),
+				client.MatchingLabels{legacyAppLabel: "ghost-" + namespace})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(*deployments.Items[0].Spec.Replicas).To(BeZero())
+
+			deleteGhost(ctx, client.ObjectKeyFromObject(ghost))
+		})
+	})
+
+	Context("When the Service is configured", func() {
+		const resourceName = "service-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{Tag: "alpine"},
+					Service: blogv2.GhostServiceSpec{
+						Type:        corev1.ServiceTypeNodePort,
+						Port:        8080,
+						Annotations: map[string]string{"example.com/team": "marketing"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should allocate a node port and drop it when switching to ClusterIP", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			service := &corev1.Service{}
+			serviceName := types.NamespacedName{Name: svcNamePrefix + resourceName, Namespace: "default"}
+			Expect(k8sClient.Get(ctx, serviceName, service)).To(Succeed())
+			Expect(service.Spec.Ports[0].Port).To(Equal(int32(8080)))
+			Expect(service.Spec.Ports[0].NodePort).NotTo(BeZero())
+			Expect(service.Annotations).To(HaveKeyWithValue("example.com/team", "marketing"))
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.NodePort).To(Equal(service.Spec.Ports[0].NodePort))
+
+			ghost.Spec.Service.Type = corev1.ServiceTypeClusterIP
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, serviceName, service)).To(Succeed())
+			Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			Expect(service.Spec.Ports[0].NodePort).To(BeZero())
+		})
+
+		It("should remove the annotations the Ghost stops declaring and keep foreign ones", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			service := &corev1.Service{}
+			serviceName := types.NamespacedName{Name: svcNamePrefix + resourceName, Namespace: "default"}
+			Expect(k8sClient.Get(ctx, serviceName, service)).To(Succeed())
+			service.Annotations["example.com/owner"] = "ops"
+			Expect(k8sClient.Update(ctx, service)).To(Succeed())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			ghost.Spec.Service.Annotations = map[string]string{"example.com/cost-center": "42"}
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, serviceName, service)).To(Succeed())
+			Expect(service.Annotations).NotTo(HaveKey("example.com/team"))
+			Expect(service.Annotations).To(HaveKeyWithValue("example.com/cost-center", "42"))
+			Expect(service.Annotations).To(HaveKeyWithValue("example.com/owner", "ops"))
+		})
+	})
+
+	Context("When the Ghost uses an external MySQL database", func() {
+		const resourceName = "mysql-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{Tag: "alpine"},
+					Database: blogv2.GhostDatabaseSpec{
+						Client: blogv2.DatabaseClientMySQL,
+						Host:   "mysql.default.svc",
+						PasswordSecretRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "mysql-blog-db"},
+							Key:                  "password",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "mysql-blog-db", Namespace: "default"}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, secret))).To(Succeed())
+		})
+
+		It("should report the missing Secret and configure MySQL once it exists", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).To(HaveOccurred())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Conditions).To(ContainElement(And(
+				HaveField("Type", "DatabaseReady"),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", "SecretNotFound"),
+			)))
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "mysql-blog-db", Namespace: "default"},
+				StringData: map[string]string{"password": "secret"},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployments := &appsv1.DeploymentList{}
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+				client.MatchingLabels{instanceLabel: resourceName})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			env := deployments.Items[0].Spec.Template.Spec.Containers[0].Env
+			Expect(env).To(ContainElement(corev1.EnvVar{Name: "database__client", Value: "mysql"}))
+			Expect(env).To(ContainElement(corev1.EnvVar{Name: "database__connection__host", Value: "mysql.default.svc"}))
+			Expect(env).NotTo(ContainElement(HaveField("Name", "database__connection__filename")))
+			Expect(deployments.Items[0].Spec.Strategy.Type).To(Equal(appsv1.RollingUpdateDeploymentStrategyType))
+		})
+	})
+
+	Context("When the Ghost reads its MySQL user from a Secret", func() {
+		const resourceName = "mysql-user-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		userRef := &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "mysql-user-blog-db"},
+			Key:                  "username",
+		}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{Tag: "alpine"},
+					Database: blogv2.GhostDatabaseSpec{
+						Client:        blogv2.DatabaseClientMySQL,
+						Host:          "mysql.default.svc",
+						UserSecretRef: userRef,
+						PasswordSecretRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "mysql-user-blog-db"},
+							Key:                  "password",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "mysql-user-blog-db", Namespace: "default"}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, secret))).To(Succeed())
+		})
+
+		It("should require the user key and pass it to Ghost and the backup Jobs", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "mysql-user-blog-db", Namespace: "default"},
+				StringData: map[string]string{"password": "secret"},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).To(HaveOccurred())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Conditions).To(ContainElement(And(
+				HaveField("Type", "DatabaseReady"),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", "SecretKeyNotFound"),
+			)))
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(secret), secret)).To(Succeed())
+			secret.Data["username"] = []byte("blog")
+			Expect(k8sClient.Update(ctx, secret)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			userEnv := corev1.EnvVar{
+				Name:      "database__connection__user",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: userRef},
+			}
+			deployments := &appsv1.DeploymentList{}
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+				client.MatchingLabels{instanceLabel: resourceName})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(deployments.Items[0].Spec.Template.Spec.Containers[0].Env).To(ContainElement(userEnv))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(mysqlClientEnv(ghost)).To(ContainElement(corev1.EnvVar{
+				Name:      "DB_USER",
+				ValueFrom: &corev1.EnvVarSource{SecretKeyRef: userRef},
+			}))
+		})
+	})
+
+	Context("When the Ghost uses a managed MySQL database", func() {
+		const resourceName = "managed-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image:    blogv2.GhostImageSpec{Tag: "alpine"},
+					Database: blogv2.GhostDatabaseSpec{Managed: true},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should provision MySQL and hold Ghost back until it is ready", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).NotTo(BeZero())
+
+			mysqlName := types.NamespacedName{Name: mysqlNamePrefix + resourceName, Namespace: "default"}
+			secret := &corev1.Secret{}
+			Expect(k8sClient.Get(ctx, mysqlName, secret)).To(Succeed())
+			Expect(secret.Data).To(HaveKey(mysqlRootPasswordKey))
+			Expect(secret.Data).To(HaveKey(mysqlPasswordKey))
+			Expect(k8sClient.Get(ctx, mysqlName, &corev1.Service{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, mysqlName, &appsv1.StatefulSet{})).To(Succeed())
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: mysqlPvcNamePrefix + resourceName, Namespace: "default"},
+				&corev1.PersistentVolumeClaim{})).To(Succeed())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Conditions).To(ContainElement(And(
+				HaveField("Type", "DatabaseReady"),
+				HaveField("Status", metav1.ConditionFalse),
+				HaveField("Reason", "MySQLStarting"),
+			)))
+
+			deployments := &appsv1.DeploymentList{}
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+				client.MatchingLabels{instanceLabel: resourceName, nameLabel: "ghost"})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(deployments.Items[0].Spec.Template.Spec.InitContainers).To(HaveLen(1))
+			Expect(deployments.Items[0].Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "database__connection__host", Value: mysqlNamePrefix + resourceName}))
+		})
+	})
+
+	Context("When the Ghost is exposed through an Ingress", func() {
+		const resourceName = "ingress-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{Tag: "alpine"},
+					Ingress: &blogv2.GhostIngressSpec{
+						Hosts:  []string{"blog.example.com", "www.blog.example.com"},
+						Issuer: "letsencrypt",
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should create the Ingress, set the url and remove the Ingress with the block", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			ingress := &networkingv1.Ingress{}
+			ingressName := types.NamespacedName{Name: ingressNamePrefix + resourceName, Namespace: "default"}
+			Expect(k8sClient.Get(ctx, ingressName, ingress)).To(Succeed())
+			Expect(ingress.Annotations).To(HaveKeyWithValue(clusterIssuerAnnotation, "letsencrypt"))
+			Expect(ingress.Spec.Rules).To(HaveLen(2))
+			Expect(ingress.Spec.TLS).To(HaveLen(1))
+			Expect(ingress.Spec.TLS[0].SecretName).To(Equal(tlsSecretNamePrefix + resourceName))
+
+			deployments := &appsv1.DeploymentList{}
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+				client.MatchingLabels{instanceLabel: resourceName})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(deployments.Items[0].Spec.Template.Spec.Containers[0].Env).To(ContainElement(
+				corev1.EnvVar{Name: "url", Value: "https://blog.example.com"}))
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			ghost.Spec.Ingress = nil
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, ingressName, ingress)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should remove the annotations the Ghost stops declaring", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			ghost.Spec.Ingress.Issuer = ""
+			ghost.Spec.Ingress.Annotations = map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "10m"}
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ingressNamePrefix + resourceName, Namespace: "default"}, ingress)).To(Succeed())
+			Expect(ingress.Annotations).NotTo(HaveKey(clusterIssuerAnnotation))
+			Expect(ingress.Annotations).To(HaveKeyWithValue("nginx.ingress.kubernetes.io/proxy-body-size", "10m"))
+			Expect(ingress.Spec.TLS).To(BeEmpty())
+		})
+	})
+
+	Context("When the Ghost is exposed through an HTTPRoute", func() {
+		const resourceName = "route-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{Tag: "alpine"},
+					HTTPRoute: &blogv2.GhostHTTPRouteSpec{
+						ParentRefs: []blogv2.GhostParentRef{{Name: "gateway"}},
+						Hostnames:  []string{"blog.example.com", "www.example.com"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should remove the hostnames the Ghost stops declaring", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			route := &gatewayv1.HTTPRoute{}
+			routeKey := types.NamespacedName{Name: httpRouteNamePrefix + resourceName, Namespace: "default"}
+			Expect(k8sClient.Get(ctx, routeKey, route)).To(Succeed())
+			Expect(route.Spec.Hostnames).To(HaveLen(2))
+
+			By("leaving the server defaults alone")
+			generation := route.Generation
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, routeKey, route)).To(Succeed())
+			Expect(route.Generation).To(Equal(generation))
+
+			By("removing a hostname")
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			ghost.Spec.HTTPRoute.Hostnames = []string{"blog.example.com"}
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, routeKey, route)).To(Succeed())
+			Expect(route.Spec.Hostnames).To(Equal([]gatewayv1.Hostname{"blog.example.com"}))
+
+			By("removing every hostname")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			ghost.Spec.HTTPRoute.Hostnames = nil
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, routeKey, route)).To(Succeed())
+			Expect(route.Spec.Hostnames).To(BeEmpty())
+		})
+	})
+
+	Context("When the Deployment is rolling out", func() {
+		const resourceName = "rollout-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should only report the Ghost ready once the pods are available", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).NotTo(BeZero())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionProgressing)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(ghost.Status.Conditions, conditionAvailable)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(ghost.Status.Conditions, "GhostReady")).To(BeTrue())
+			Expect(meta.FindStatusCondition(ghost.Status.Conditions, "GhostReady").ObservedGeneration).To(Equal(ghost.Generation))
+
+			By("completing the rollout")
+			deployments := &appsv1.DeploymentList{}
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+				client.MatchingLabels{instanceLabel: resourceName})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			deployment := &deployments.Items[0]
+			deployment.Status.ObservedGeneration = deployment.Generation
+			deployment.Status.Replicas = 1
+			deployment.Status.UpdatedReplicas = 1
+			deployment.Status.ReadyReplicas = 1
+			deployment.Status.AvailableReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			result, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionAvailable)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(ghost.Status.Conditions, conditionProgressing)).To(BeTrue())
+			Expect(meta.IsStatusConditionFalse(ghost.Status.Conditions, conditionDegraded)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(ghost.Status.Conditions, "GhostReady")).To(BeTrue())
+		})
+	})
+
+	Context("When the Deployment drifted from the Ghost", func() {
+		const resourceName = "drift-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should restore the declared spec and remove duplicate Deployments", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployments := &appsv1.DeploymentList{}
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+				client.MatchingLabels{instanceLabel: resourceName})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			deployment := deployments.Items[0].DeepCopy()
+
+			By("editing the Deployment by hand")
+			replicas := int32(3)
+			deployment.Spec.Replicas = &replicas
+			container := &deployment.Spec.Template.Spec.Containers[0]
+			container.Env = append(container.Env, corev1.EnvVar{Name: "EXTRA", Value: "1"})
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			By("adding a duplicate Deployment")
+			duplicate := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: deploymentNamePrefix + "zzzzz", Namespace: "default", Labels: deployment.Labels},
+				Spec:       *deployments.Items[0].Spec.DeepCopy(),
+			}
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(controllerutil.SetControllerReference(ghost, duplicate, k8sClient.Scheme())).To(Succeed())
+			Expect(k8sClient.Create(ctx, duplicate)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(deployment), deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "EXTRA")))
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(duplicate), duplicate)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("When only list items were added to the Deployment", func() {
+		const resourceName = "append-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should remove the env var and container added by hand", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			key := types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+
+			By("reconciling an untouched Deployment")
+			generation := deployment.Generation
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Generation).To(Equal(generation))
+
+			By("appending an env var")
+			container := &deployment.Spec.Template.Spec.Containers[0]
+			container.Env = append(container.Env, corev1.EnvVar{Name: "EXTRA", Value: "1"})
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "EXTRA")))
+
+			By("appending a container")
+			deployment.Spec.Template.Spec.Containers = append(deployment.Spec.Template.Spec.Containers,
+				corev1.Container{Name: "sidecar", Image: "busybox"})
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers).To(HaveLen(1))
+		})
+	})
+
+	Context("When a Deployment with a generated name exists", func() {
+		const resourceName = "adopt-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should stop it before a Deployment with the stable name takes over", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			By("creating a Deployment the way older versions did")
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			replicas := int32(1)
+			generated, err := createDesiredDeployment(ghost, pvcNamePrefix+resourceName, &replicas)
+			Expect(err).NotTo(HaveOccurred())
+			generated.Name = ""
+			generated.GenerateName = deploymentNamePrefix
+			Expect(controllerutil.SetControllerReference(ghost, generated, k8sClient.Scheme())).To(Succeed())
+			Expect(k8sClient.Create(ctx, generated)).To(Succeed())
+			generated.Status.Replicas = 1
+			generated.Status.AvailableReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, generated)).To(Succeed())
+
+			By("scaling it down first, since its pods hold the SQLite database")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployments := &appsv1.DeploymentList{}
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+				client.MatchingLabels{instanceLabel: resourceName})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(deployments.Items[0].Name).To(Equal(generated.Name))
+			Expect(*deployments.Items[0].Spec.Replicas).To(BeZero())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.DeploymentName).To(Equal(generated.Name))
+
+			By("waiting for its pods to stop")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+				client.MatchingLabels{instanceLabel: resourceName})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(deployments.Items[0].Name).To(Equal(generated.Name))
+
+			By("replacing it once its pods are gone")
+			Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(generated), generated)).To(Succeed())
+			generated.Status.Replicas = 0
+			generated.Status.AvailableReplicas = 0
+			Expect(k8sClient.Status().Update(ctx, generated)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.List(ctx, deployments, client.InNamespace("default"),
+				client.MatchingLabels{instanceLabel: resourceName})).To(Succeed())
+			Expect(deployments.Items).To(HaveLen(1))
+			Expect(deployments.Items[0].Name).To(Equal(deploymentNamePrefix + resourceName))
+			Expect(*deployments.Items[0].Spec.Replicas).To(Equal(int32(1)))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.DeploymentName).To(Equal(deploymentNamePrefix + resourceName))
+		})
+	})
+
+	Context("When a Ghost retaining its content is deleted", func() {
+		const resourceName = "retain-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image:   blogv2.GhostImageSpec{Tag: "alpine"},
+					Storage: blogv2.GhostStorageSpec{RetentionPolicy: blogv2.RetentionPolicyRetain},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		It("should orphan the PVC before letting the Ghost go", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Finalizers).To(ContainElement(ghostFinalizer))
+			pvc := &corev1.PersistentVolumeClaim{}
+			pvcKey := types.NamespacedName{Name: pvcNamePrefix + resourceName, Namespace: "default"}
+			Expect(k8sClient.Get(ctx, pvcKey, pvc)).To(Succeed())
+			Expect(metav1.IsControlledBy(pvc, ghost)).To(BeTrue())
+
+			By("deleting the Ghost")
+			Expect(k8sClient.Delete(ctx, ghost)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, pvcKey, pvc)).To(Succeed())
+			Expect(pvc.OwnerReferences).To(BeEmpty())
+			err = k8sClient.Get(ctx, typeNamespacedName, ghost)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("When a Ghost snapshotting its content is deleted", func() {
+		const resourceName = "snapshot-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image:   blogv2.GhostImageSpec{Tag: "alpine"},
+					Storage: blogv2.GhostStorageSpec{RetentionPolicy: blogv2.RetentionPolicySnapshot},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		It("should hold the Ghost until the VolumeSnapshot is ready to use", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("deleting the Ghost")
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ghost)).To(Succeed())
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Finalizers).To(ContainElement(ghostFinalizer))
+			snapshot := &snapshotv1.VolumeSnapshot{}
+			snapshotKey := types.NamespacedName{Name: snapshotNameForClaim(ghost, pvcNamePrefix+resourceName), Namespace: "default"}
+			Expect(k8sClient.Get(ctx, snapshotKey, snapshot)).To(Succeed())
+			Expect(*snapshot.Spec.Source.PersistentVolumeClaimName).To(Equal(pvcNamePrefix + resourceName))
+			Expect(snapshot.OwnerReferences).To(BeEmpty())
+
+			By("letting the Ghost go once the snapshot is ready")
+			readyToUse := true
+			snapshot.Status = &snapshotv1.VolumeSnapshotStatus{ReadyToUse: &readyToUse}
+			Expect(k8sClient.Status().Update(ctx, snapshot)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, typeNamespacedName, ghost)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(k8sClient.Delete(ctx, snapshot)).To(Succeed())
+		})
+	})
+
+	Context("When the storage of the Ghost is resized", func() {
+		const resourceName = "resize-blog"
+		const storageClassName = "expandable"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			allowExpansion := true
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta:           metav1.ObjectMeta{Name: storageClassName},
+				Provisioner:          "example.com/test",
+				AllowVolumeExpansion: &allowExpansion,
+			}
+			Expect(k8sClient.Create(ctx, storageClass)).To(Succeed())
+
+			className := storageClassName
+			size := resource.MustParse("1Gi")
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image:   blogv2.GhostImageSpec{Tag: "alpine"},
+					Storage: blogv2.GhostStorageSpec{Size: &size, StorageClassName: &className},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+			Expect(k8sClient.Delete(ctx, &storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: storageClassName}})).To(Succeed())
+		})
+
+		It("should expand the PVC and refuse to shrink it", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			pvcKey := types.NamespacedName{Name: pvcNamePrefix + resourceName, Namespace: "default"}
+			Expect(k8sClient.Get(ctx, pvcKey, pvc)).To(Succeed())
+			Expect(*pvc.Spec.StorageClassName).To(Equal(storageClassName))
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("1Gi"))
+			pvc.Status.Phase = corev1.ClaimBound
+			pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}
+			Expect(k8sClient.Status().Update(ctx, pvc)).To(Succeed())
+
+			By("growing the volume")
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			size := resource.MustParse("2Gi")
+			ghost.Spec.Storage.Size = &size
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).NotTo(BeZero())
+
+			Expect(k8sClient.Get(ctx, pvcKey, pvc)).To(Succeed())
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("2Gi"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionStorageResizing)).To(BeTrue())
+
+			By("shrinking the volume")
+			size = resource.MustParse("512Mi")
+			ghost.Spec.Storage.Size = &size
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, pvcKey, pvc)).To(Succeed())
+			Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal("2Gi"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			cond := meta.FindStatusCondition(ghost.Status.Conditions, conditionStorageResizing)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal("ShrinkNotSupported"))
+		})
+	})
+
+	Context("When the Ghost pins its image", func() {
+		const resourceName = "image-blog"
+		const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{
+						Repository:       "registry.example.com/ghost",
+						Tag:              "5.96.0",
+						PullPolicy:       corev1.PullIfNotPresent,
+						ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry-credentials"}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should run the image and report the digest the pods pulled", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}, deployment)).To(Succeed())
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Containers[0].Image).To(Equal("registry.example.com/ghost:5.96.0"))
+			Expect(podSpec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+			Expect(podSpec.ImagePullSecrets).To(ConsistOf(corev1.LocalObjectReference{Name: "registry-credentials"}))
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Image).To(Equal("registry.example.com/ghost:5.96.0"))
+
+			By("running a pod of the Deployment")
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName + "-pod", Namespace: "default", Labels: deployment.Spec.Template.Labels},
+				Spec:       *podSpec.DeepCopy(),
+			}
+			pod.Spec.Volumes = nil
+			pod.Spec.Containers[0].VolumeMounts = nil
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status.Phase = corev1.PodRunning
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name:    podSpec.Containers[0].Name,
+				Image:   "registry.example.com/ghost:5.96.0",
+				ImageID: "registry.example.com/ghost@" + digest,
+			}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Image).To(Equal("registry.example.com/ghost:5.96.0@" + digest))
+			Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+		})
+	})
+
+	Context("When the Ghost site is configured", func() {
+		const resourceName = "configured-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			useGravatar := false
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{Tag: "alpine"},
+					Config: blogv2.GhostConfigSpec{
+						URL:   "https://blog.example.com",
+						Admin: &blogv2.GhostAdminSpec{URL: "https://admin.example.com"},
+						Mail: &blogv2.GhostMailSpec{
+							Transport: blogv2.MailTransportSMTP,
+							From:      "blog@example.com",
+							SMTP: &blogv2.GhostSMTPSpec{
+								Host:   "smtp.example.com",
+								Secure: true,
+								PasswordSecretRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{Name: "smtp-credentials"},
+									Key:                  "password",
+								},
+							},
+						},
+						Privacy:  &blogv2.GhostPrivacySpec{UseGravatar: &useGravatar},
+						Logging:  &blogv2.GhostLoggingSpec{Level: "warn", Transports: []blogv2.GhostLoggingTransport{"stdout"}},
+						ExtraEnv: []corev1.EnvVar{{Name: "NODE_ENV", Value: "staging"}},
+						EnvFrom: []corev1.EnvFromSource{{
+							ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ghost-settings"}},
+						}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should render the configuration into the container env", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}, deployment)).To(Succeed())
+			container := deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.Env).To(ContainElements(
+				corev1.EnvVar{Name: "url", Value: "https://blog.example.com"},
+				corev1.EnvVar{Name: "admin__url", Value: "https://admin.example.com"},
+				corev1.EnvVar{Name: "mail__transport", Value: "SMTP"},
+				corev1.EnvVar{Name: "mail__from", Value: "blog@example.com"},
+				corev1.EnvVar{Name: "mail__options__host", Value: "smtp.example.com"},
+				corev1.EnvVar{Name: "mail__options__port", Value: "465"},
+				corev1.EnvVar{Name: "mail__options__secure", Value: "true"},
+				corev1.EnvVar{Name: "privacy__useGravatar", Value: "false"},
+				corev1.EnvVar{Name: "logging__level", Value: "warn"},
+				corev1.EnvVar{Name: "logging__transports", Value: `["stdout"]`},
+				corev1.EnvVar{Name: "NODE_ENV", Value: "staging"},
+			))
+			Expect(container.Env).To(ContainElement(HaveField("Name", "mail__options__auth__pass")))
+			Expect(container.Env).NotTo(ContainElement(corev1.EnvVar{Name: "NODE_ENV", Value: "development"}))
+			Expect(container.EnvFrom).To(HaveLen(1))
+			Expect(container.EnvFrom[0].ConfigMapRef.Name).To(Equal("ghost-settings"))
+		})
+	})
+
+	Context("When the Ghost runs in production", func() {
+		const resourceName = "production-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image:       blogv2.GhostImageSpec{Tag: "alpine"},
+					Environment: blogv2.EnvironmentProduction,
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should refuse SQLite unless overridden and apply the production defaults", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			cond := meta.FindStatusCondition(ghost.Status.Conditions, conditionValidated)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal("SQLiteInProduction"))
+			deploymentKey := types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}
+			deployment := &appsv1.Deployment{}
+			Expect(errors.IsNotFound(k8sClient.Get(ctx, deploymentKey, deployment))).To(BeTrue())
+
+			By("overriding the SQLite check without a url")
+			ghost.Spec.Database.AllowSQLiteInProduction = true
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(meta.FindStatusCondition(ghost.Status.Conditions, conditionValidated).Reason).To(Equal("URLMissing"))
+
+			By("setting the url")
+			ghost.Spec.Config.URL = "https://blog.example.com"
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionValidated)).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			container := deployment.Spec.Template.Spec.Containers[0]
+			Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "NODE_ENV", Value: "production"}))
+			Expect(container.Resources.Requests.Cpu().String()).To(Equal("250m"))
+			Expect(container.Resources.Requests.Memory().String()).To(Equal("512Mi"))
+			Expect(container.ReadinessProbe).NotTo(BeNil())
+			Expect(container.ReadinessProbe.HTTPGet.HTTPHeaders).To(ContainElement(corev1.HTTPHeader{Name: "Host", Value: "blog.example.com"}))
+			Expect(container.LivenessProbe).NotTo(BeNil())
+		})
+	})
+
+	Context("When the Ghost container is probed", func() {
+		const resourceName = "probed-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			startupFailureThreshold := int32(120)
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image:  blogv2.GhostImageSpec{Tag: "alpine"},
+					Config: blogv2.GhostConfigSpec{URL: "https://blog.example.com/news"},
+					Probes: blogv2.GhostProbesSpec{
+						Startup:  &blogv2.GhostProbeSpec{FailureThreshold: &startupFailureThreshold},
+						Liveness: &blogv2.GhostProbeSpec{Disabled: true},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should probe the site through its url with the configured thresholds", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}, deployment)).To(Succeed())
+			container := deployment.Spec.Template.Spec.Containers[0]
+
+			Expect(container.StartupProbe).NotTo(BeNil())
+			Expect(container.StartupProbe.FailureThreshold).To(Equal(int32(120)))
+			Expect(container.StartupProbe.PeriodSeconds).To(Equal(int32(10)))
+			Expect(container.LivenessProbe).To(BeNil())
+			Expect(container.ReadinessProbe).NotTo(BeNil())
+
+			httpGet := container.ReadinessProbe.HTTPGet
+			Expect(httpGet).NotTo(BeNil())
+			Expect(httpGet.Path).To(Equal("/news/ghost/api/admin/site/"))
+			Expect(httpGet.Port.IntValue()).To(Equal(2368))
+			Expect(httpGet.HTTPHeaders).To(ConsistOf(
+				corev1.HTTPHeader{Name: "Host", Value: "blog.example.com"},
+				corev1.HTTPHeader{Name: "X-Forwarded-Proto", Value: "https"},
+			))
+		})
+	})
+
+	Context("When the Ghost pods are placed and secured", func() {
+		const resourceName = "placed-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image:        blogv2.GhostImageSpec{Tag: "alpine"},
+					NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+						MaxSkew:           1,
+						TopologyKey:       "topology.kubernetes.io/zone",
+						WhenUnsatisfiable: corev1.ScheduleAnyway,
+					}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should run Ghost as non-root where the Ghost places it", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deploymentKey := types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.NodeSelector).To(Equal(map[string]string{"kubernetes.io/os": "linux"}))
+			Expect(podSpec.TopologySpreadConstraints).To(HaveLen(1))
+			Expect(podSpec.TopologySpreadConstraints[0].LabelSelector.MatchLabels).To(Equal(selectorForGhost(&blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName},
+			})))
+			Expect(*podSpec.SecurityContext.RunAsNonRoot).To(BeTrue())
+			Expect(*podSpec.SecurityContext.RunAsUser).To(Equal(int64(1000)))
+			Expect(*podSpec.SecurityContext.FSGroup).To(Equal(int64(1000)))
+			Expect(*podSpec.Containers[0].SecurityContext.ReadOnlyRootFilesystem).To(BeTrue())
+			Expect(podSpec.Containers[0].VolumeMounts).To(ContainElement(HaveField("MountPath", "/tmp")))
+
+			By("adding a toleration to the Deployment by hand")
+			deployment.Spec.Template.Spec.Tolerations = []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Tolerations).To(BeEmpty())
+
+			By("setting a priority class on the Ghost")
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			ghost.Spec.PriorityClassName = "blogs"
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.PriorityClassName).To(Equal("blogs"))
+		})
+	})
+
+	Context("When the Ghost is scaled out", func() {
+		const resourceName = "scaled-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "scaled-blog-db", Namespace: "default"},
+				StringData: map[string]string{"password": "secret"},
+			}
+			Expect(k8sClient.Create(ctx, secret)).To(Succeed())
+
+			replicas := int32(3)
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image:    blogv2.GhostImageSpec{Tag: "alpine"},
+					Replicas: &replicas,
+					Database: blogv2.GhostDatabaseSpec{
+						Client: blogv2.DatabaseClientMySQL,
+						Host:   "mysql.default.svc",
+						PasswordSecretRef: &corev1.SecretKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "scaled-blog-db"},
+							Key:                  "password",
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "scaled-blog-db", Namespace: "default"}}
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, secret))).To(Succeed())
+		})
+
+		It("should hold it at one replica until its storage is shared and then autoscale it", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			cond := meta.FindStatusCondition(ghost.Status.Conditions, conditionScalingRestricted)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal("StorageNotShared"))
+
+			deploymentKey := types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+
+			By("storing the images in object storage and autoscaling")
+			minReplicas := int32(2)
+			ghost.Spec.Storage.Adapter = "s3"
+			ghost.Spec.Autoscaling = &blogv2.GhostAutoscalingSpec{MinReplicas: &minReplicas, MaxReplicas: 5}
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(meta.FindStatusCondition(ghost.Status.Conditions, conditionScalingRestricted)).To(BeNil())
+
+			hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: hpaNamePrefix + resourceName, Namespace: "default"}, hpa)).To(Succeed())
+			Expect(hpa.Spec.ScaleTargetRef.Name).To(Equal(deploymentKey.Name))
+			Expect(*hpa.Spec.MinReplicas).To(Equal(int32(2)))
+			Expect(hpa.Spec.MaxReplicas).To(Equal(int32(5)))
+			Expect(hpa.Spec.Metrics).To(HaveLen(1))
+			Expect(*hpa.Spec.Metrics[0].Resource.Target.AverageUtilization).To(Equal(int32(80)))
+
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "storage__active", Value: "s3"}))
+
+			By("removing the minimum")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			ghost.Spec.Autoscaling.MinReplicas = nil
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: hpaNamePrefix + resourceName, Namespace: "default"}, hpa)).To(Succeed())
+			Expect(*hpa.Spec.MinReplicas).To(Equal(int32(1)))
+
+			By("removing the autoscaling")
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			ghost.Spec.Autoscaling = nil
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = k8sClient.Get(ctx, types.NamespacedName{Name: hpaNamePrefix + resourceName, Namespace: "default"}, hpa)
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+		})
+	})
+
+	Context("When the Ghost is scaled through its scale subresource", func() {
+		const resourceName = "scale-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image:    blogv2.GhostImageSpec{Tag: "alpine"},
+					Database: blogv2.GhostDatabaseSpec{Managed: true},
+					Storage:  blogv2.GhostStorageSpec{Adapter: "s3"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should report the selector and scale the Deployment", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Selector).To(Equal(labels.SelectorFromSet(selectorForGhost(ghost)).String()))
+
+			By("scaling the Ghost")
+			scale := &autoscalingv1.Scale{}
+			Expect(k8sClient.SubResource("scale").Get(ctx, ghost, scale)).To(Succeed())
+			Expect(scale.Status.Selector).To(Equal(ghost.Status.Selector))
+			scale.Spec.Replicas = 2
+			Expect(k8sClient.SubResource("scale").Update(ctx, ghost, client.WithSubResourceBody(scale))).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(*ghost.Spec.Replicas).To(Equal(int32(2)))
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(2)))
+		})
+	})
+
+	Context("When the Ghost backs up on a schedule", func() {
+		const resourceName = "scheduled-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{Tag: "alpine"},
+					Backup: &blogv2.GhostBackupScheduleSpec{
+						Schedule: "0 3 * * *",
+						Target:   blogv2.BackupTarget{PVC: &blogv2.PVCBackupTarget{ClaimName: "backups"}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.DeleteAllOf(ctx, &blogv2.GhostBackup{}, client.InNamespace("default"),
+				client.MatchingLabels{backupGhostLabel: resourceName})).To(Succeed())
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should report the next backup and raise BackupFailing after consecutive failures", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.NextScheduledBackup).NotTo(BeNil())
+			Expect(ghost.Status.NextScheduledBackup.Hour()).To(Equal(3))
+			Expect(ghost.Status.LastSuccessfulBackup).To(BeNil())
+
+			By("failing more scheduled backups in a row than the threshold")
+			for i := 0; i <= backupFailureThreshold; i++ {
+				backup := &blogv2.GhostBackup{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("%s-%d", resourceName, i),
+						Namespace: "default",
+						Labels:    map[string]string{backupGhostLabel: resourceName, backupScheduledLabel: "true"},
+					},
+					Spec: blogv2.GhostBackupSpec{GhostName: resourceName, Target: ghost.Spec.Backup.Target},
+				}
+				Expect(k8sClient.Create(ctx, backup)).To(Succeed())
+				backup.Status.Phase = blogv2.GhostBackupPhaseFailed
+				Expect(k8sClient.Status().Update(ctx, backup)).To(Succeed())
+			}
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			condition := meta.FindStatusCondition(ghost.Status.Conditions, conditionBackupFailing)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("ConsecutiveFailures"))
+
+			By("pruning the failed backups the condition does not need")
+			backups := &blogv2.GhostBackupList{}
+			Expect(k8sClient.List(ctx, backups, client.InNamespace("default"),
+				client.MatchingLabels{backupGhostLabel: resourceName})).To(Succeed())
+			Expect(backups.Items).To(HaveLen(backupFailureThreshold))
+
+			By("suspending the schedule")
+			ghost.Spec.Backup.Suspend = true
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.NextScheduledBackup).To(BeNil())
+		})
+	})
+
+	Context("When the SQLite database is replicated", func() {
+		const resourceName = "replicated-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{Tag: "alpine"},
+					Database: blogv2.GhostDatabaseSpec{
+						SQLite: &blogv2.GhostSQLiteSpec{
+							Replication: &blogv2.SQLiteReplicationSpec{
+								S3: blogv2.S3BackupTarget{
+									Bucket:               "replicas",
+									Prefix:               "blogs",
+									Endpoint:             "http://minio.minio.svc:9000",
+									CredentialsSecretRef: corev1.LocalObjectReference{Name: "minio-credentials"},
+								},
+								Retention: &metav1.Duration{Duration: 72 * time.Hour},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should stream the database with a Litestream sidecar and restore it into an empty volume", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := &appsv1.Deployment{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}, deployment)).To(Succeed())
+			Expect(deployment.Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+			Expect(deployment.Spec.Strategy.RollingUpdate).To(BeNil())
+			podSpec := deployment.Spec.Template.Spec
+			Expect(podSpec.Containers).To(HaveLen(2))
+			sidecar := podSpec.Containers[1]
+			Expect(sidecar.Image).To(Equal(litestreamImage))
+			Expect(sidecar.EnvFrom[0].SecretRef.Name).To(Equal("minio-credentials"))
+			Expect(sidecar.Env).To(HaveLen(1))
+			Expect(sidecar.Env[0].Value).To(And(
+				ContainSubstring(`"path":"`+sqliteDatabasePath+`"`),
+				ContainSubstring(`"path":"blogs/default/`+resourceName+`"`),
+				ContainSubstring(`"force-path-style":true`),
+				ContainSubstring(`"retention":"72h0m0s"`),
+			))
+			Expect(podSpec.InitContainers).To(HaveLen(1))
+			Expect(podSpec.InitContainers[0].Args[0]).To(ContainSubstring("-if-db-not-exists -if-replica-exists"))
+
+			By("leaving the sidecar out of the migration Job")
+			job, err := generateMigrationJob(&blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Database: blogv2.GhostDatabaseSpec{SQLite: &blogv2.GhostSQLiteSpec{Replication: &blogv2.SQLiteReplicationSpec{}}}},
+			}, pvcNamePrefix+resourceName, migrationJobNamePrefix+resourceName)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(job.Spec.Template.Spec.Containers).To(HaveLen(1))
+		})
+	})
+
+	Context("When the Ghost image is upgraded", func() {
+		const resourceName = "upgraded-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Image: blogv2.GhostImageSpec{Tag: "5.95.0-alpine"},
+					Upgrade: &blogv2.GhostUpgradeSpec{
+						BackupTarget: &blogv2.BackupTarget{PVC: &blogv2.PVCBackupTarget{ClaimName: "backups"}},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(k8sClient.DeleteAllOf(ctx, &blogv2.GhostRestore{}, client.InNamespace("default"),
+				client.MatchingLabels{backupGhostLabel: resourceName})).To(Succeed())
+			Expect(k8sClient.DeleteAllOf(ctx, &blogv2.GhostBackup{}, client.InNamespace("default"),
+				client.MatchingLabels{backupGhostLabel: resourceName})).To(Succeed())
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should back up, migrate and roll out the new image, and roll back when it never becomes available", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+			reconcileGhost := func() {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			ghost := &blogv2.Ghost{}
+			deployment := &appsv1.Deployment{}
+			deploymentKey := types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}
+
+			reconcileGhost()
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("ghost:5.95.0-alpine"))
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade).To(BeNil())
+
+			By("backing up before anything changes")
+			ghost.Spec.Image.Tag = "5.96.0-alpine"
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			upgrade := ghost.Status.Upgrade
+			Expect(upgrade).NotTo(BeNil())
+			Expect(upgrade.Phase).To(Equal(blogv2.GhostUpgradePhaseBackingUp))
+			Expect(upgrade.FromImage).To(Equal("ghost:5.95.0-alpine"))
+			Expect(upgrade.ToImage).To(Equal("ghost:5.96.0-alpine"))
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("ghost:5.95.0-alpine"))
+
+			By("starting over when the upgrade status was not written")
+			ghost.Status.Upgrade = nil
+			Expect(k8sClient.Status().Update(ctx, ghost)).To(Succeed())
+			reconcileGhost()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade.BackupName).To(Equal(upgrade.BackupName))
+			backups := &blogv2.GhostBackupList{}
+			Expect(k8sClient.List(ctx, backups, client.InNamespace("default"),
+				client.MatchingLabels{backupGhostLabel: resourceName})).To(Succeed())
+			Expect(backups.Items).To(HaveLen(1))
+
+			backup := &blogv2.GhostBackup{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: upgrade.BackupName, Namespace: "default"}, backup)).To(Succeed())
+			Expect(backup.Spec.Target.PVC.ClaimName).To(Equal("backups"))
+			backup.Status.Phase = blogv2.GhostBackupPhaseSucceeded
+			Expect(k8sClient.Status().Update(ctx, backup)).To(Succeed())
+
+			By("migrating the database with the new image while the Ghost is scaled down")
+			reconcileGhost()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade.Phase).To(Equal(blogv2.GhostUpgradePhaseMigrating))
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(0)))
+
+			reconcileGhost()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade.MigrationJobName).To(Equal(
+				boundedName(migrationJobNamePrefix, fmt.Sprintf("%s-%d", resourceName, upgrade.ObservedGeneration))))
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ghost.Status.Upgrade.MigrationJobName, Namespace: "default"}, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("ghost:5.96.0-alpine"))
+
+			now := metav1.Now()
+			job.Status.StartTime = &now
+			job.Status.CompletionTime = &now
+			job.Status.Succeeded = 1
+			job.Status.Conditions = []batchv1.JobCondition{
+				{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue, LastTransitionTime: now},
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: now},
+			}
+			Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+
+			By("rolling out the new image")
+			reconcileGhost()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade.Phase).To(Equal(blogv2.GhostUpgradePhaseRollingOut))
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("ghost:5.96.0-alpine"))
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+
+			By("rolling back once the deadline passed")
+			past := metav1.NewTime(time.Now().Add(-time.Hour))
+			ghost.Status.Upgrade.RolloutStartTime = &past
+			Expect(k8sClient.Status().Update(ctx, ghost)).To(Succeed())
+			reconcileGhost()
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade.Phase).To(Equal(blogv2.GhostUpgradePhaseRollingBack))
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("ghost:5.95.0-alpine"))
+			Expect(ghost.Status.Upgrade.RestoreName).To(Equal(fmt.Sprintf("%s-rollback-%d", resourceName, upgrade.ObservedGeneration)))
+			restore := &blogv2.GhostRestore{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: ghost.Status.Upgrade.RestoreName, Namespace: "default"}, restore)).To(Succeed())
+			Expect(restore.Spec.Source.BackupName).To(Equal(upgrade.BackupName))
+
+			restore.Status.Phase = blogv2.GhostRestorePhaseSucceeded
+			Expect(k8sClient.Status().Update(ctx, restore)).To(Succeed())
+			reconcileGhost()
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade.Phase).To(Equal(blogv2.GhostUpgradePhaseRolledBack))
+			Expect(ghost.Status.Upgrade.CompletionTime).NotTo(BeNil())
+
+			By("keeping the previous image while the Ghost asks for the same one")
+			replicas := int32(1)
+			ghost.Spec.Replicas = &replicas
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			reconcileGhost()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade.Phase).To(Equal(blogv2.GhostUpgradePhaseRolledBack))
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("ghost:5.95.0-alpine"))
+		})
+	})
+
+	Context("When the Ghost image is upgraded without a backup target", func() {
+		const resourceName = "unbacked-blog"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			resource := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "5.95.0-alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, resource)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			deleteGhost(ctx, typeNamespacedName)
+		})
+
+		It("should block the upgrade until the backup is explicitly skipped", func() {
+			controllerReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+			reconcileGhost := func() {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			ghost := &blogv2.Ghost{}
+			deployment := &appsv1.Deployment{}
+			deploymentKey := types.NamespacedName{Name: deploymentNamePrefix + resourceName, Namespace: "default"}
+
+			reconcileGhost()
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			ghost.Spec.Image.Tag = "5.96.0-alpine"
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			reconcileGhost()
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade.Phase).To(Equal(blogv2.GhostUpgradePhaseBlocked))
+			Expect(meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionUpgradeBlocked)).To(BeTrue())
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("ghost:5.95.0-alpine"))
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+
+			By("skipping the backup")
+			ghost.Spec.Upgrade = &blogv2.GhostUpgradeSpec{SkipBackup: true}
+			Expect(k8sClient.Update(ctx, ghost)).To(Succeed())
+			reconcileGhost()
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ghost)).To(Succeed())
+			Expect(ghost.Status.Upgrade.Phase).To(Equal(blogv2.GhostUpgradePhaseMigrating))
+			Expect(ghost.Status.Upgrade.BackupName).To(BeEmpty())
+			Expect(meta.FindStatusCondition(ghost.Status.Conditions, conditionUpgradeBlocked)).To(BeNil())
+		})
+	})
+})
+
+// deleteGhost deletes the Ghost and reconciles it once, so its finalizer
+// applies the retention policy and lets it go
+func deleteGhost(ctx context.Context, key types.NamespacedName) {
+	ghost := &blogv2.Ghost{}
+	Expect(k8sClient.Get(ctx, key, ghost)).To(Succeed())
+	Expect(k8sClient.Delete(ctx, ghost)).To(Succeed())
+
+	controllerReconciler := &GhostReconciler{
+		Client:  k8sClient,
+		Scheme:  k8sClient.Scheme(),
+		recoder: record.NewFakeRecorder(100),
+	}
+	_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	Expect(err).NotTo(HaveOccurred())
+	err = k8sClient.Get(ctx, key, ghost)
+	Expect(errors.IsNotFound(err)).To(BeTrue())
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	blogv2 "example.com/api/v2"
+)
+
+const sqliteDatabasePath = "/var/lib/ghost/content/data/ghost.db"
+
+// databaseEnvPrefix prefixes every Ghost setting of the database section
+const databaseEnvPrefix = "database__"
+
+// deploymentStrategy returns how the Ghost pods are replaced. A SQLite
+// database is a file only one Ghost may open, so the old pods stop before the
+// new ones start instead of overlapping during a rolling update.
+func deploymentStrategy(ghost *blogv2.Ghost) appsv1.DeploymentStrategy {
+	if ghost.Spec.DatabaseClient() == blogv2.DatabaseClientMySQL {
+		return appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType}
+	}
+	return appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+}
+
+// databaseConnection returns the database settings Ghost connects with. For a
+// managed database they point at the StatefulSet run by the operator.
+func databaseConnection(ghost *blogv2.Ghost) blogv2.GhostDatabaseSpec {
+	if !ghost.Spec.Database.Managed {
+		return ghost.Spec.Database
+	}
+	return blogv2.GhostDatabaseSpec{
+		Client:  blogv2.DatabaseClientMySQL,
+		Managed: true,
+		Host:    mysqlNamePrefix + ghost.ObjectMeta.Name,
+		Port:    3306,
+		Name:    "ghost",
+		User:    "ghost",
+		PasswordSecretRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: mysqlNamePrefix + ghost.ObjectMeta.Name},
+			Key:                  mysqlPasswordKey,
+		},
+	}
+}
+
+// databaseEnvVars returns the env vars configuring the database connection of Ghost.
+func databaseEnvVars(ghost *blogv2.Ghost) []corev1.EnvVar {
+	db := databaseConnection(ghost)
+	if ghost.Spec.DatabaseClient() != blogv2.DatabaseClientMySQL {
+		return []corev1.EnvVar{
+			{Name: "database__client", Value: string(blogv2.DatabaseClientSQLite)},
+			{Name: "database__connection__filename", Value: sqliteDatabasePath},
+		}
+	}
+
+	port := db.Port
+	if port == 0 {
+		port = 3306
+	}
+	name := db.Name
+	if name == "" {
+		name = "ghost"
+	}
+	user := db.User
+	if user == "" {
+		user = "ghost"
+	}
+
+	userEnv := corev1.EnvVar{Name: "database__connection__user", Value: user}
+	if db.UserSecretRef != nil {
+		userEnv = corev1.EnvVar{
+			Name:      "database__connection__user",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: db.UserSecretRef},
+		}
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "database__client", Value: string(blogv2.DatabaseClientMySQL)},
+		{Name: "database__connection__host", Value: db.Host},
+		{Name: "database__connection__port", Value: strconv.Itoa(int(port))},
+		userEnv,
+		{Name: "database__connection__database", Value: name},
+	}
+	if db.PasswordSecretRef != nil {
+		env = append(env, corev1.EnvVar{
+			Name: "database__connection__password",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: db.PasswordSecretRef,
+			},
+		})
+	}
+	return env
+}
+
+// withDatabaseEnv replaces any database settings in env with the ones of the Ghost.
+func withDatabaseEnv(ghost *blogv2.Ghost, env []corev1.EnvVar) []corev1.EnvVar {
+	result := make([]corev1.EnvVar, 0, len(env))
+	for _, e := range env {
+		if !strings.HasPrefix(e.Name, databaseEnvPrefix) {
+			result = append(result, e)
+		}
+	}
+	return append(result, databaseEnvVars(ghost)...)
+}
+
+// checkDatabase verifies the database settings of the Ghost can be resolved.
+// On failure it returns the reason to report in the DatabaseReady condition.
+// A managed database is checked through its StatefulSet instead.
+func (r *GhostReconciler) checkDatabase(ctx context.Context, ghost *blogv2.Ghost) (string, error) {
+	db := ghost.Spec.Database
+	if ghost.Spec.DatabaseClient() != blogv2.DatabaseClientMySQL || db.Managed {
+		return "", nil
+	}
+
+	if db.Host == "" {
+		return "HostMissing", fmt.Errorf("spec.database.host is required for mysql")
+	}
+	if db.PasswordSecretRef == nil {
+		return "SecretMissing", fmt.Errorf("spec.database.passwordSecretRef is required for mysql")
+	}
+
+	if db.UserSecretRef != nil {
+		if reason, err := r.checkSecretKey(ctx, ghost, "spec.database.userSecretRef", db.UserSecretRef); err != nil {
+			return reason, err
+		}
+	}
+	return r.checkSecretKey(ctx, ghost, "spec.database.passwordSecretRef", db.PasswordSecretRef)
+}
+
+// checkSecretKey verifies the key of a Secret referenced by field exists.
+// On failure it returns the reason to report in the DatabaseReady condition.
+func (r *GhostReconciler) checkSecretKey(ctx context.Context, ghost *blogv2.Ghost, field string, ref *corev1.SecretKeySelector) (string, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: ref.Name}, secret)
+	if err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			return "SecretNotFound", fmt.Errorf("secret %s referenced by %s not found", ref.Name, field)
+		}
+		return "SecretNotFound", err
+	}
+	if _, ok := secret.Data[ref.Key]; !ok {
+		return "SecretKeyNotFound", fmt.Errorf("secret %s has no key %s", ref.Name, ref.Key)
+	}
+	return "", nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	blogv2 "example.com/api/v2"
+)
+
+// conditionValidated is False while the Ghost spec cannot be rolled out
+const conditionValidated = "Validated"
+
+// nodeEnvName is the env var Ghost picks its environment from
+const nodeEnvName = "NODE_ENV"
+
+// productionRequests are the resource requests of a production Ghost that
+// does not set its own.
+var productionRequests = corev1.ResourceList{
+	corev1.ResourceCPU:    resource.MustParse("250m"),
+	corev1.ResourceMemory: resource.MustParse("512Mi"),
+}
+
+// ghostEnvironment returns the environment of the Ghost, defaulting to development
+func ghostEnvironment(ghost *blogv2.Ghost) blogv2.Environment {
+	if ghost.Spec.Environment == "" {
+		return blogv2.EnvironmentDevelopment
+	}
+	return ghost.Spec.Environment
+}
+
+// validateEnvironment verifies the Ghost can run in its environment. On
+// failure it returns the reason to report in the Validated condition.
+func validateEnvironment(ghost *blogv2.Ghost) (string, error) {
+	if ghostEnvironment(ghost) != blogv2.EnvironmentProduction {
+		return "", nil
+	}
+	if ghost.Spec.DatabaseClient() == blogv2.DatabaseClientSQLite && !ghost.Spec.Database.AllowSQLiteInProduction {
+		return "SQLiteInProduction", fmt.Errorf("production requires a mysql database, set spec.database.allowSQLiteInProduction to run on sqlite3 anyway")
+	}
+	if ghostURL(ghost) == "" {
+		return "URLMissing", fmt.Errorf("production requires spec.config.url, an Ingress or an HTTPRoute hostname")
+	}
+	return "", nil
+}
+
+// withEnvironmentEnv sets NODE_ENV in env to the environment of the Ghost.
+func withEnvironmentEnv(ghost *blogv2.Ghost, env []corev1.EnvVar) []corev1.EnvVar {
+	result := make([]corev1.EnvVar, 0, len(env)+1)
+	result = append(result, corev1.EnvVar{Name: nodeEnvName, Value: string(ghostEnvironment(ghost))})
+	for _, e := range env {
+		if e.Name != nodeEnvName {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+// containerResources returns the resources of the Ghost container. A
+// production Ghost gets default requests when it sets none.
+func containerResources(ghost *blogv2.Ghost) corev1.ResourceRequirements {
+	resources := *ghost.Spec.Resources.DeepCopy()
+	if ghostEnvironment(ghost) == blogv2.EnvironmentProduction && len(resources.Requests) == 0 {
+		resources.Requests = productionRequests.DeepCopy()
+	}
+	return resources
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	blogv2 "example.com/api/v2"
+)
+
+// ghostFinalizer holds a deleted Ghost back until the retention policy of its
+// PVCs has been applied. The children themselves are still removed by the
+// garbage collector through their owner references.
+const ghostFinalizer = "blog.example.com/finalizer"
+
+// retainedClaimNames returns the PVCs the retention policy applies to: the
+// content of the Ghost and, when it is managed, the MySQL data.
+func (r *GhostReconciler) retainedClaimNames(ctx context.Context, ghost *blogv2.Ghost) ([]string, error) {
+	dataClaim, err := r.dataClaimName(ctx, ghost)
+	if err != nil {
+		return nil, err
+	}
+	claims := []string{dataClaim}
+	if ghost.Spec.Database.Managed {
+		claims = append(claims, mysqlPvcNamePrefix+ghost.ObjectMeta.Name)
+	}
+	return claims, nil
+}
+
+// finalizeGhost applies the retention policy of a deleted Ghost and removes
+// its finalizer once done. A Snapshot policy keeps the Ghost around until
+// every snapshot is ready to use; switching the policy to Retain unblocks a
+// snapshot that cannot be taken.
+func (r *GhostReconciler) finalizeGhost(ctx context.Context, ghost *blogv2.Ghost) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(ghost, ghostFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	claims, err := r.retainedClaimNames(ctx, ghost)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	policy := ghost.Spec.RetentionPolicy()
+	switch policy {
+	case blogv2.RetentionPolicyRetain:
+		for _, claim := range claims {
+			if err := r.retainPVC(ctx, ghost, claim); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	case blogv2.RetentionPolicySnapshot:
+		allReady := true
+		for _, claim := range claims {
+			ready, err := r.snapshotPVC(ctx, ghost, claim)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			allReady = allReady && ready
+		}
+		if !allReady {
+			log.Info("Waiting for VolumeSnapshots to become ready", "ghost", ghost.Name)
+			return ctrl.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+	}
+
+	controllerutil.RemoveFinalizer(ghost, ghostFinalizer)
+	if err := r.Update(ctx, ghost); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "Finalized", "Retention policy "+string(policy)+" applied")
+	log.Info("Ghost finalized", "ghost", ghost.Name, "retentionPolicy", policy)
+	return ctrl.Result{}, nil
+}
+
+// retainPVC strips the owner reference of the Ghost from the PVC, so the
+// garbage collector leaves it alone.
+func (r *GhostReconciler) retainPVC(ctx context.Context, ghost *blogv2.Ghost, claimName string) error {
+	log := log.FromContext(ctx)
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: claimName}, pvc)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	var ownerReferences []metav1.OwnerReference
+	for _, ref := range pvc.OwnerReferences {
+		if ref.UID != ghost.UID {
+			ownerReferences = append(ownerReferences, ref)
+		}
+	}
+	if len(ownerReferences) == len(pvc.OwnerReferences) {
+		return nil
+	}
+	pvc.OwnerReferences = ownerReferences
+	if err := r.Update(ctx, pvc); err != nil {
+		return err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "PVCRetained", "PVC "+claimName+" retained")
+	log.Info("PVC retained", "pvc", claimName)
+	return nil
+}
+
+// snapshotPVC takes a VolumeSnapshot of the PVC and reports whether it is
+// ready to use. The snapshot is not owned by the Ghost, so it outlives it.
+func (r *GhostReconciler) snapshotPVC(ctx context.Context, ghost *blogv2.Ghost, claimName string) (bool, error) {
+	log := log.FromContext(ctx)
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: claimName}, pvc); err != nil {
+		if errors.IsNotFound(err) {
+			// Nothing left to snapshot
+			return true, nil
+		}
+		return false, err
+	}
+
+	snapshot := &snapshotv1.VolumeSnapshot{}
+	snapshotName := snapshotNameForClaim(ghost, claimName)
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: snapshotName}, snapshot)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		if meta.IsNoMatchError(err) {
+			r.recoder.Event(ghost, corev1.EventTypeWarning, "VolumeSnapshotFailed", "VolumeSnapshots are not available in the cluster")
+		}
+		return false, err
+	}
+
+	if err == nil {
+		if snapshot.Status != nil && snapshot.Status.Error != nil && snapshot.Status.Error.Message != nil {
+			r.recoder.Event(ghost, corev1.EventTypeWarning, "VolumeSnapshotFailed",
+				"VolumeSnapshot "+snapshotName+" failed: "+*snapshot.Status.Error.Message)
+		}
+		return snapshot.Status != nil && snapshot.Status.ReadyToUse != nil && *snapshot.Status.ReadyToUse, nil
+	}
+
+	snapshot = &snapshotv1.VolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      snapshotName,
+			Namespace: ghost.ObjectMeta.Namespace,
+			Labels:    pvc.Labels,
+		},
+		Spec: snapshotv1.VolumeSnapshotSpec{
+			Source:                  snapshotv1.VolumeSnapshotSource{PersistentVolumeClaimName: &claimName},
+			VolumeSnapshotClassName: ghost.Spec.Storage.VolumeSnapshotClassName,
+		},
+	}
+	if err := r.Create(ctx, snapshot); err != nil {
+		if meta.IsNoMatchError(err) {
+			r.recoder.Event(ghost, corev1.EventTypeWarning, "VolumeSnapshotFailed", "VolumeSnapshots are not available in the cluster")
+		}
+		return false, err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "VolumeSnapshotCreated", "VolumeSnapshot "+snapshotName+" of PVC "+claimName+" created")
+	log.Info("VolumeSnapshot created", "volumesnapshot", snapshotName, "pvc", claimName)
+	return false, nil
+}
+
+// snapshotNameForClaim names the snapshot after the PVC and the UID of the
+// Ghost, so a Ghost recreated under the same name does not reuse it.
+func snapshotNameForClaim(ghost *blogv2.Ghost, claimName string) string {
+	uid := string(ghost.UID)
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	return fmt.Sprintf("%s-%s", claimName, uid)
+}
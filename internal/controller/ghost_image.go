@@ -0,0 +1,70 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	blogv2 "example.com/api/v2"
+)
+
+// updateResolvedImage records the image the Ghost runs in its status. Once a
+// pod of the current template runs, the digest it pulled is added to it.
+func (r *GhostReconciler) updateResolvedImage(ctx context.Context, ghost *blogv2.Ghost, deployment *appsv1.Deployment) error {
+	desired := deployment.Spec.Template.Spec.Containers[0]
+	ghost.Status.Image = desired.Image
+
+	podList := &corev1.PodList{}
+	if err := r.List(ctx, podList, client.InNamespace(ghost.ObjectMeta.Namespace), client.MatchingLabels(selectorForGhost(ghost))); err != nil {
+		return err
+	}
+	for i := range podList.Items {
+		if digest := podImageDigest(&podList.Items[i], desired); digest != "" {
+			ghost.Status.Image = strings.SplitN(desired.Image, "@", 2)[0] + "@" + digest
+			return nil
+		}
+	}
+	return nil
+}
+
+// podImageDigest returns the digest of the image the container runs in the
+// pod, if the pod runs the desired image.
+func podImageDigest(pod *corev1.Pod, desired corev1.Container) string {
+	if pod.Status.Phase != corev1.PodRunning || !pod.DeletionTimestamp.IsZero() {
+		return ""
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == desired.Name && container.Image != desired.Image {
+			return ""
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name != desired.Name {
+			continue
+		}
+		// e.g. docker-pullable://ghost@sha256:... or docker.io/library/ghost@sha256:...
+		if _, digest, found := strings.Cut(status.ImageID, "@"); found {
+			return digest
+		}
+	}
+	return ""
+}
@@ -0,0 +1,390 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	blogv2 "example.com/api/v2"
+)
+
+const ingressNamePrefix = "ghost-ingress-"
+const httpRouteNamePrefix = "ghost-route-"
+const tlsSecretNamePrefix = "ghost-tls-"
+
+// clusterIssuerAnnotation asks cert-manager to issue the Ingress certificate
+const clusterIssuerAnnotation = "cert-manager.io/cluster-issuer"
+
+// addOrUpdateIngress makes the Ingress of the Ghost match spec.ingress, and
+// removes it once the block is gone.
+func (r *GhostReconciler) addOrUpdateIngress(ctx context.Context, ghost *blogv2.Ghost) error {
+	log := log.FromContext(ctx)
+	ingress := &networkingv1.Ingress{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: ingressNamePrefix + ghost.ObjectMeta.Name}, ingress)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(ingress, ghost) {
+		return fmt.Errorf("ingress %s already exists and is not owned by Ghost %s", ingress.Name, ghost.Name)
+	}
+
+	if ghost.Spec.Ingress == nil {
+		if !exists {
+			return nil
+		}
+		if err := r.Delete(ctx, ingress); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "IngressDeleted", "Ingress deleted successfully")
+		log.Info("Ingress deleted", "ingress", ingress.Name)
+		return nil
+	}
+
+	desiredIngress := generateDesiredIngress(ghost)
+	if !exists {
+		if err := controllerutil.SetControllerReference(ghost, desiredIngress, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, desiredIngress); err != nil {
+			return err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "IngressCreated", "Ingress created successfully")
+		log.Info("Ingress created", "ingress", desiredIngress.Name)
+		return nil
+	}
+
+	changed := syncAnnotations(ingress, ingressAnnotations(ghost))
+	if desiredIngress.Spec.IngressClassName == nil {
+		// Keep the default class the cluster assigned
+		desiredIngress.Spec.IngressClassName = ingress.Spec.IngressClassName
+	}
+	if !equality.Semantic.DeepEqual(desiredIngress.Spec, ingress.Spec) {
+		ingress.Spec = desiredIngress.Spec
+		changed = true
+	}
+	if !changed {
+		log.Info("Ingress is up to date, no action required", "ingress", ingress.Name)
+		return nil
+	}
+	if err := r.Update(ctx, ingress); err != nil {
+		return err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "IngressUpdated", "Ingress updated successfully")
+	log.Info("Ingress updated", "ingress", ingress.Name)
+	return nil
+}
+
+// ingressAnnotations returns the annotations the Ingress of the Ghost carries
+func ingressAnnotations(ghost *blogv2.Ghost) map[string]string {
+	spec := ghost.Spec.Ingress
+	annotations := map[string]string{}
+	for k, v := range spec.Annotations {
+		annotations[k] = v
+	}
+	if spec.Issuer != "" {
+		annotations[clusterIssuerAnnotation] = spec.Issuer
+	}
+	return annotations
+}
+
+func generateDesiredIngress(ghost *blogv2.Ghost) *networkingv1.Ingress {
+	spec := ghost.Spec.Ingress
+	pathType := networkingv1.PathTypePrefix
+
+	rules := make([]networkingv1.IngressRule, 0, len(spec.Hosts))
+	for _, host := range spec.Hosts {
+		rules = append(rules, networkingv1.IngressRule{
+			Host: host,
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{
+							Path:     ingressPath(ghost),
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{
+								Service: &networkingv1.IngressServiceBackend{
+									Name: svcNamePrefix + ghost.ObjectMeta.Name,
+									Port: networkingv1.ServiceBackendPort{Number: ghost.Spec.ServicePort()},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ingressNamePrefix + ghost.ObjectMeta.Name,
+			Namespace: ghost.ObjectMeta.Namespace,
+			Labels:    labelsForGhost(ghost),
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: spec.ClassName,
+			Rules:            rules,
+		},
+	}
+	if secretName := tlsSecretName(ghost); secretName != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{
+			{
+				Hosts:      spec.Hosts,
+				SecretName: secretName,
+			},
+		}
+	}
+	syncAnnotations(ingress, ingressAnnotations(ghost))
+	return ingress
+}
+
+// addOrUpdateHTTPRoute makes the HTTPRoute of the Ghost match spec.httpRoute,
+// and removes it once the block is gone. Clusters without the Gateway API
+// are only a problem when a route is actually requested.
+func (r *GhostReconciler) addOrUpdateHTTPRoute(ctx context.Context, ghost *blogv2.Ghost) error {
+	log := log.FromContext(ctx)
+	route := &gatewayv1.HTTPRoute{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: httpRouteNamePrefix + ghost.ObjectMeta.Name}, route)
+	if ghost.Spec.HTTPRoute == nil && meta.IsNoMatchError(err) {
+		return nil
+	}
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(route, ghost) {
+		return fmt.Errorf("HTTPRoute %s already exists and is not owned by Ghost %s", route.Name, ghost.Name)
+	}
+
+	if ghost.Spec.HTTPRoute == nil {
+		if !exists {
+			return nil
+		}
+		if err := r.Delete(ctx, route); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "HTTPRouteDeleted", "HTTPRoute deleted successfully")
+		log.Info("HTTPRoute deleted", "httproute", route.Name)
+		return nil
+	}
+
+	desiredRoute := generateDesiredHTTPRoute(ghost)
+	if !exists {
+		if err := controllerutil.SetControllerReference(ghost, desiredRoute, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, desiredRoute); err != nil {
+			return err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "HTTPRouteCreated", "HTTPRoute created successfully")
+		log.Info("HTTPRoute created", "httproute", desiredRoute.Name)
+		return nil
+	}
+
+	copyHTTPRouteDefaults(&desiredRoute.Spec, &route.Spec)
+	if equality.Semantic.DeepEqual(desiredRoute.Spec, route.Spec) {
+		log.Info("HTTPRoute is up to date, no action required", "httproute", route.Name)
+		return nil
+	}
+	route.Spec = desiredRoute.Spec
+	if err := r.Update(ctx, route); err != nil {
+		return err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "HTTPRouteUpdated", "HTTPRoute updated successfully")
+	log.Info("HTTPRoute updated", "httproute", route.Name)
+	return nil
+}
+
+// copyHTTPRouteDefaults copies the fields the API server defaults and the
+// desired spec leaves empty from the existing spec, so that both can be
+// compared as a whole.
+func copyHTTPRouteDefaults(desired, existing *gatewayv1.HTTPRouteSpec) {
+	for i := range desired.ParentRefs {
+		if i >= len(existing.ParentRefs) {
+			break
+		}
+		if desired.ParentRefs[i].Group == nil {
+			desired.ParentRefs[i].Group = existing.ParentRefs[i].Group
+		}
+		if desired.ParentRefs[i].Kind == nil {
+			desired.ParentRefs[i].Kind = existing.ParentRefs[i].Kind
+		}
+	}
+	for i := range desired.Rules {
+		if i >= len(existing.Rules) {
+			break
+		}
+		rule, existingRule := &desired.Rules[i], &existing.Rules[i]
+		if rule.Matches == nil {
+			rule.Matches = existingRule.Matches
+		}
+		for j := range rule.BackendRefs {
+			if j >= len(existingRule.BackendRefs) {
+				break
+			}
+			ref, existingRef := &rule.BackendRefs[j].BackendRef, &existingRule.BackendRefs[j].BackendRef
+			if ref.Group == nil {
+				ref.Group = existingRef.Group
+			}
+			if ref.Kind == nil {
+				ref.Kind = existingRef.Kind
+			}
+			if ref.Weight == nil {
+				ref.Weight = existingRef.Weight
+			}
+		}
+	}
+}
+
+func generateDesiredHTTPRoute(ghost *blogv2.Ghost) *gatewayv1.HTTPRoute {
+	spec := ghost.Spec.HTTPRoute
+
+	parentRefs := make([]gatewayv1.ParentReference, 0, len(spec.ParentRefs))
+	for _, ref := range spec.ParentRefs {
+		parentRef := gatewayv1.ParentReference{Name: gatewayv1.ObjectName(ref.Name)}
+		if ref.Namespace != "" {
+			namespace := gatewayv1.Namespace(ref.Namespace)
+			parentRef.Namespace = &namespace
+		}
+		if ref.SectionName != "" {
+			sectionName := gatewayv1.SectionName(ref.SectionName)
+			parentRef.SectionName = &sectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+
+	hostnames := make([]gatewayv1.Hostname, 0, len(spec.Hostnames))
+	for _, hostname := range spec.Hostnames {
+		hostnames = append(hostnames, gatewayv1.Hostname(hostname))
+	}
+
+	port := gatewayv1.PortNumber(ghost.Spec.ServicePort())
+	return &gatewayv1.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      httpRouteNamePrefix + ghost.ObjectMeta.Name,
+			Namespace: ghost.ObjectMeta.Namespace,
+			Labels:    labelsForGhost(ghost),
+		},
+		Spec: gatewayv1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1.CommonRouteSpec{ParentRefs: parentRefs},
+			Hostnames:       hostnames,
+			Rules: []gatewayv1.HTTPRouteRule{
+				{
+					BackendRefs: []gatewayv1.HTTPBackendRef{
+						{
+							BackendRef: gatewayv1.BackendRef{
+								BackendObjectReference: gatewayv1.BackendObjectReference{
+									Name: gatewayv1.ObjectName(svcNamePrefix + ghost.ObjectMeta.Name),
+									Port: &port,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ghostURL returns the public url of the blog, built from the primary host of
+// the Ingress or else the HTTPRoute. It is empty when neither is configured.
+func ghostURL(ghost *blogv2.Ghost) string {
+	if ghost.Spec.Config.URL != "" {
+		return ghost.Spec.Config.URL
+	}
+	if ingress := ghost.Spec.Ingress; ingress != nil && len(ingress.Hosts) > 0 {
+		scheme := "http"
+		if tlsSecretName(ghost) != "" {
+			scheme = "https"
+		}
+		path := ingressPath(ghost)
+		if path == "/" {
+			path = ""
+		}
+		return scheme + "://" + ingress.Hosts[0] + path
+	}
+	if route := ghost.Spec.HTTPRoute; route != nil && len(route.Hostnames) > 0 {
+		return "http://" + route.Hostnames[0]
+	}
+	return ""
+}
+
+// withURLEnv replaces the url setting in env with the one of the Ghost.
+func withURLEnv(ghost *blogv2.Ghost, env []corev1.EnvVar) []corev1.EnvVar {
+	result := make([]corev1.EnvVar, 0, len(env)+1)
+	for _, e := range env {
+		if e.Name != "url" {
+			result = append(result, e)
+		}
+	}
+	if url := ghostURL(ghost); url != "" {
+		result = append(result, corev1.EnvVar{Name: "url", Value: url})
+	}
+	return result
+}
+
+// ingressPath returns the path the blog is served under, / by default.
+func ingressPath(ghost *blogv2.Ghost) string {
+	if ghost.Spec.Ingress == nil || ghost.Spec.Ingress.Path == "" {
+		return "/"
+	}
+	return ghost.Spec.Ingress.Path
+}
+
+// tlsSecretName returns the Secret holding the Ingress certificate, if TLS is enabled.
+func tlsSecretName(ghost *blogv2.Ghost) string {
+	spec := ghost.Spec.Ingress
+	switch {
+	case spec == nil:
+		return ""
+	case spec.TLSSecretName != "":
+		return spec.TLSSecretName
+	case spec.Issuer != "":
+		return tlsSecretNamePrefix + ghost.ObjectMeta.Name
+	}
+	return ""
+}
+
+// mergeAnnotations sets the given annotations on obj and reports whether any changed.
+func mergeAnnotations(obj metav1.Object, annotations map[string]string) bool {
+	current := obj.GetAnnotations()
+	changed := false
+	for k, v := range annotations {
+		if current[k] != v {
+			if current == nil {
+				current = map[string]string{}
+			}
+			current[k] = v
+			changed = true
+		}
+	}
+	if changed {
+		obj.SetAnnotations(current)
+	}
+	return changed
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	blogv2 "example.com/api/v2"
+)
+
+const litestreamImage = "litestream/litestream:0.3.13"
+
+// litestreamConfigEnv holds the Litestream configuration in the containers.
+// Litestream reads LITESTREAM_CONFIG itself, so it cannot be used.
+const litestreamConfigEnv = "REPLICATION_CONFIG"
+
+// litestreamConfigPath is where the containers write the configuration, on the tmp volume
+const litestreamConfigPath = "/tmp/litestream.yml"
+
+// litestreamConfig is the Litestream configuration file. It is rendered as
+// JSON, which Litestream reads as YAML.
+type litestreamConfig struct {
+	DBs []litestreamDB `json:"dbs"`
+}
+
+type litestreamDB struct {
+	Path     string              `json:"path"`
+	Replicas []litestreamReplica `json:"replicas"`
+}
+
+type litestreamReplica struct {
+	Type           string `json:"type"`
+	Bucket         string `json:"bucket"`
+	Path           string `json:"path"`
+	Endpoint       string `json:"endpoint,omitempty"`
+	Region         string `json:"region,omitempty"`
+	ForcePathStyle bool   `json:"force-path-style,omitempty"`
+	SyncInterval   string `json:"sync-interval,omitempty"`
+	Retention      string `json:"retention,omitempty"`
+}
+
+// sqliteReplication returns the replication of the Ghost database, nil when
+// it is not replicated
+func sqliteReplication(ghost *blogv2.Ghost) *blogv2.SQLiteReplicationSpec {
+	if ghost.Spec.DatabaseClient() != blogv2.DatabaseClientSQLite || ghost.Spec.Database.SQLite == nil {
+		return nil
+	}
+	return ghost.Spec.Database.SQLite.Replication
+}
+
+// litestreamConfigFor renders the Litestream configuration of the Ghost
+func litestreamConfigFor(ghost *blogv2.Ghost, replication *blogv2.SQLiteReplicationSpec) string {
+	s3 := replication.S3
+	replica := litestreamReplica{
+		Type:     "s3",
+		Bucket:   s3.Bucket,
+		Path:     path.Join(strings.Trim(s3.Prefix, "/"), ghost.Namespace, ghost.Name),
+		Endpoint: s3.Endpoint,
+		Region:   s3.Region,
+		// Compatible storages like MinIO are addressed by path
+		ForcePathStyle: s3.Endpoint != "",
+	}
+	if replication.SyncInterval != nil {
+		replica.SyncInterval = replication.SyncInterval.Duration.String()
+	}
+	if replication.Retention != nil {
+		replica.Retention = replication.Retention.Duration.String()
+	}
+	config := litestreamConfig{DBs: []litestreamDB{{Path: sqliteDatabasePath, Replicas: []litestreamReplica{replica}}}}
+	// Marshalling plain strings and bools cannot fail
+	data, _ := json.Marshal(config)
+	return string(data)
+}
+
+// litestreamContainer returns a container running Litestream with the
+// configuration of the Ghost. It writes the configuration before running
+// script, and mounts the content volume of the Ghost and its tmp volume.
+func litestreamContainer(ghost *blogv2.Ghost, replication *blogv2.SQLiteReplicationSpec, name, script string) corev1.Container {
+	return corev1.Container{
+		Name:    name,
+		Image:   litestreamImage,
+		Command: []string{"sh", "-ec"},
+		Args:    []string{`printf '%s' "$` + litestreamConfigEnv + `" > ` + litestreamConfigPath + "\n" + script},
+		Env:     []corev1.EnvVar{{Name: litestreamConfigEnv, Value: litestreamConfigFor(ghost, replication)}},
+		EnvFrom: []corev1.EnvFromSource{{
+			SecretRef: &corev1.SecretEnvSource{LocalObjectReference: replication.S3.CredentialsSecretRef},
+		}},
+		SecurityContext: containerSecurityContext(ghost),
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "ghost-data", MountPath: ghostInstallPath + "/content"},
+			{Name: "tmp", MountPath: "/tmp"},
+		},
+	}
+}
+
+// applySQLiteReplication adds Litestream to the Ghost pod when its database
+// is replicated: an init container restoring the database from the replica
+// when the content volume holds none, and a sidecar streaming the changes.
+func applySQLiteReplication(ghost *blogv2.Ghost, podSpec *corev1.PodSpec) {
+	replication := sqliteReplication(ghost)
+	if replication == nil {
+		return
+	}
+	restore := litestreamContainer(ghost, replication, "restore-database",
+		`mkdir -p `+filepath.Dir(sqliteDatabasePath)+`
+exec litestream restore -config `+litestreamConfigPath+` -if-db-not-exists -if-replica-exists `+sqliteDatabasePath)
+	replicate := litestreamContainer(ghost, replication, "litestream",
+		`exec litestream replicate -config `+litestreamConfigPath)
+	podSpec.InitContainers = append(podSpec.InitContainers, restore)
+	podSpec.Containers = append(podSpec.Containers, replicate)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	blogv2 "example.com/api/v2"
+)
+
+// Older operator versions named every child after the namespace and selected
+// pods with a single "app: ghost-<namespace>" label. Only one Ghost per
+// namespace could work that way.
+const legacyAppLabel = "app"
+
+func legacyAppLabelValue(ghost *blogv2.Ghost) string {
+	return "ghost-" + ghost.ObjectMeta.Namespace
+}
+
+// listGhostDeployments returns the Deployments selecting the pods of the
+// Ghost, along with the ones of older operator versions selecting them with
+// the legacy label. Deployment selectors are immutable, so those are replaced
+// rather than updated.
+func (r *GhostReconciler) listGhostDeployments(ctx context.Context, ghost *blogv2.Ghost) ([]appsv1.Deployment, error) {
+	deployments := &appsv1.DeploymentList{}
+	err := r.List(ctx, deployments, client.InNamespace(ghost.ObjectMeta.Namespace),
+		client.MatchingLabels(selectorForGhost(ghost)))
+	if err != nil {
+		return nil, err
+	}
+	legacy := &appsv1.DeploymentList{}
+	err = r.List(ctx, legacy, client.InNamespace(ghost.ObjectMeta.Namespace),
+		client.MatchingLabels{legacyAppLabel: legacyAppLabelValue(ghost)})
+	if err != nil {
+		return nil, err
+	}
+	return append(deployments.Items, legacy.Items...), nil
+}
+
+// dataClaimName returns the name of the PVC holding the Ghost content.
+func (r *GhostReconciler) dataClaimName(ctx context.Context, ghost *blogv2.Ghost) (string, error) {
+	return lookupDataClaimName(ctx, r.Client, ghost)
+}
+
+// lookupDataClaimName returns the name of the PVC holding the Ghost content. A
+// claim created by an older operator version keeps its namespace based name,
+// since PVCs cannot be renamed without losing the blog data.
+func lookupDataClaimName(ctx context.Context, c client.Reader, ghost *blogv2.Ghost) (string, error) {
+	if ghost.Spec.Storage.ExistingClaim != "" {
+		return ghost.Spec.Storage.ExistingClaim, nil
+	}
+	legacyName := pvcNamePrefix + ghost.ObjectMeta.Namespace
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := c.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: legacyName}, pvc)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return "", err
+	}
+	if err == nil && metav1.IsControlledBy(pvc, ghost) {
+		return legacyName, nil
+	}
+	return pvcNamePrefix + ghost.ObjectMeta.Name, nil
+}
+
+// migrateLegacyResources adopts the namespace named children of a Ghost that
+// were created by an older operator version. The PVC is relabelled and kept,
+// the Service is replaced by its per-Ghost counterpart. The Deployment is
+// adopted and replaced along with the ones with a generated name, see
+// listGhostDeployments. Objects not controlled by this Ghost are never touched.
+func (r *GhostReconciler) migrateLegacyResources(ctx context.Context, ghost *blogv2.Ghost) error {
+	log := log.FromContext(ctx)
+	namespace := ghost.ObjectMeta.Namespace
+
+	// Relabel the legacy PVC so it is selected like any other child
+	pvc := &corev1.PersistentVolumeClaim{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: pvcNamePrefix + namespace}, pvc)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err == nil && metav1.IsControlledBy(pvc, ghost) && pvc.Labels[instanceLabel] != ghost.ObjectMeta.Name {
+		if pvc.Labels == nil {
+			pvc.Labels = map[string]string{}
+		}
+		for k, v := range labelsForGhost(ghost) {
+			pvc.Labels[k] = v
+		}
+		if err := r.Update(ctx, pvc); err != nil {
+			return err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "PVCMigrated", "Legacy PVC "+pvc.Name+" adopted")
+		log.Info("Legacy PVC adopted", "pvc", pvc.Name)
+	}
+
+	// Replace the legacy Service. When the Ghost is named after its namespace
+	// the name does not change and only the selector needs updating.
+	service := &corev1.Service{}
+	err = r.Get(ctx, client.ObjectKey{Namespace: namespace, Name: svcNamePrefix + namespace}, service)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err == nil && metav1.IsControlledBy(service, ghost) {
+		if service.Name != svcNamePrefix+ghost.ObjectMeta.Name {
+			if err := r.Delete(ctx, service); client.IgnoreNotFound(err) != nil {
+				return err
+			}
+			r.recoder.Event(ghost, corev1.EventTypeNormal, "ServiceMigrated", "Legacy Service "+service.Name+" removed")
+			log.Info("Legacy Service removed", "service", service.Name)
+		} else if _, ok := service.Spec.Selector[legacyAppLabel]; ok {
+			service.Labels = labelsForGhost(ghost)
+			service.Spec.Selector = selectorForGhost(ghost)
+			if err := r.Update(ctx, service); err != nil {
+				return err
+			}
+			r.recoder.Event(ghost, corev1.EventTypeNormal, "ServiceMigrated", "Legacy Service "+service.Name+" adopted")
+			log.Info("Legacy Service adopted", "service", service.Name)
+		}
+	}
+
+	return nil
+}
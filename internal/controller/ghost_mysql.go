@@ -0,0 +1,274 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"example.com/assets"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	blogv2 "example.com/api/v2"
+)
+
+// The StatefulSet, its headless Service and the credentials Secret of a
+// managed database share the same name.
+const mysqlNamePrefix = "ghost-mysql-"
+const mysqlPvcNamePrefix = "ghost-mysql-pvc-"
+
+// Keys of the generated credentials Secret
+const (
+	mysqlRootPasswordKey = "root-password"
+	mysqlPasswordKey     = "password"
+)
+
+// waitForMySQLImage runs the init container holding Ghost back until MySQL accepts connections
+const waitForMySQLImage = "busybox:1.36"
+
+// addOrUpdateManagedDatabase makes sure the MySQL StatefulSet of the Ghost and
+// everything it needs exist, and reports whether MySQL is ready to serve.
+func (r *GhostReconciler) addOrUpdateManagedDatabase(ctx context.Context, ghost *blogv2.Ghost) (bool, error) {
+	if err := r.addMySQLSecretIfNotExists(ctx, ghost); err != nil {
+		return false, err
+	}
+	if err := r.addMySQLPvcIfNotExists(ctx, ghost); err != nil {
+		return false, err
+	}
+	if err := r.addMySQLServiceIfNotExists(ctx, ghost); err != nil {
+		return false, err
+	}
+	return r.addMySQLStatefulSetIfNotExists(ctx, ghost)
+}
+
+func (r *GhostReconciler) addMySQLSecretIfNotExists(ctx context.Context, ghost *blogv2.Ghost) error {
+	log := log.FromContext(ctx)
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: mysqlNamePrefix + ghost.ObjectMeta.Name}, secret)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	if err == nil {
+		// Secret exists, never regenerate the passwords MySQL was initialized with
+		if !metav1.IsControlledBy(secret, ghost) {
+			return fmt.Errorf("secret %s already exists and is not owned by Ghost %s", secret.Name, ghost.Name)
+		}
+		return nil
+	}
+
+	rootPassword, err := generatePassword()
+	if err != nil {
+		return err
+	}
+	password, err := generatePassword()
+	if err != nil {
+		return err
+	}
+
+	secret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mysqlNamePrefix + ghost.ObjectMeta.Name,
+			Namespace: ghost.ObjectMeta.Namespace,
+			Labels:    labelsForManagedDatabase(ghost),
+		},
+		StringData: map[string]string{
+			mysqlRootPasswordKey: rootPassword,
+			mysqlPasswordKey:     password,
+		},
+	}
+	if err := controllerutil.SetControllerReference(ghost, secret, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, secret); err != nil {
+		return err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "MySQLSecretCreated", "MySQL credentials generated successfully")
+	log.Info("MySQL Secret created", "secret", secret.Name)
+	return nil
+}
+
+func (r *GhostReconciler) addMySQLPvcIfNotExists(ctx context.Context, ghost *blogv2.Ghost) error {
+	log := log.FromContext(ctx)
+	pvc := &corev1.PersistentVolumeClaim{}
+	pvcName := mysqlPvcNamePrefix + ghost.ObjectMeta.Name
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: pvcName}, pvc)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	if err == nil {
+		if !metav1.IsControlledBy(pvc, ghost) {
+			return fmt.Errorf("PVC %s already exists and is not owned by Ghost %s", pvcName, ghost.Name)
+		}
+		return nil
+	}
+
+	desiredPVC, err := assets.GetPersistentVolumeClaimFromFile("manifests/mysql_data_pvc.yaml")
+	if err != nil {
+		return err
+	}
+	desiredPVC.Name = pvcName
+	desiredPVC.Namespace = ghost.ObjectMeta.Namespace
+	desiredPVC.Labels = labelsForManagedDatabase(ghost)
+
+	if err := controllerutil.SetControllerReference(ghost, desiredPVC, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, desiredPVC); err != nil {
+		return err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "MySQLPVCCreated", "MySQL PVC created successfully")
+	log.Info("MySQL PVC created", "pvc", pvcName)
+	return nil
+}
+
+func (r *GhostReconciler) addMySQLServiceIfNotExists(ctx context.Context, ghost *blogv2.Ghost) error {
+	log := log.FromContext(ctx)
+	service := &corev1.Service{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: mysqlNamePrefix + ghost.ObjectMeta.Name}, service)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	if err == nil {
+		if !metav1.IsControlledBy(service, ghost) {
+			return fmt.Errorf("service %s already exists and is not owned by Ghost %s", service.Name, ghost.Name)
+		}
+		return nil
+	}
+
+	desiredService, err := assets.GetServiceFromFile("manifests/mysql_service.yaml")
+	if err != nil {
+		return err
+	}
+	desiredService.Name = mysqlNamePrefix + ghost.ObjectMeta.Name
+	desiredService.Namespace = ghost.ObjectMeta.Namespace
+	desiredService.Labels = labelsForManagedDatabase(ghost)
+	desiredService.Spec.Selector = selectorForManagedDatabase(ghost)
+
+	if err := controllerutil.SetControllerReference(ghost, desiredService, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, desiredService); err != nil {
+		return err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "MySQLServiceCreated", "MySQL Service created successfully")
+	log.Info("MySQL Service created", "service", desiredService.Name)
+	return nil
+}
+
+func (r *GhostReconciler) addMySQLStatefulSetIfNotExists(ctx context.Context, ghost *blogv2.Ghost) (bool, error) {
+	log := log.FromContext(ctx)
+	statefulSet := &appsv1.StatefulSet{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: mysqlNamePrefix + ghost.ObjectMeta.Name}, statefulSet)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return false, err
+	}
+
+	if err == nil {
+		if !metav1.IsControlledBy(statefulSet, ghost) {
+			return false, fmt.Errorf("StatefulSet %s already exists and is not owned by Ghost %s", statefulSet.Name, ghost.Name)
+		}
+		return statefulSet.Status.ReadyReplicas > 0, nil
+	}
+
+	desiredStatefulSet, err := createDesiredMySQLStatefulSet(ghost)
+	if err != nil {
+		return false, err
+	}
+	if err := controllerutil.SetControllerReference(ghost, desiredStatefulSet, r.Scheme); err != nil {
+		return false, err
+	}
+	if err := r.Create(ctx, desiredStatefulSet); err != nil {
+		return false, err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "MySQLStatefulSetCreated", "MySQL StatefulSet created successfully")
+	log.Info("MySQL StatefulSet created", "statefulset", desiredStatefulSet.Name)
+	return false, nil
+}
+
+func createDesiredMySQLStatefulSet(ghost *blogv2.Ghost) (*appsv1.StatefulSet, error) {
+	statefulSet, err := assets.GetStatefulSetFromFile("manifests/mysql_statefulset.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	// initialize the object
+	name := mysqlNamePrefix + ghost.ObjectMeta.Name
+	statefulSet.Name = name
+	statefulSet.Namespace = ghost.ObjectMeta.Namespace
+	statefulSet.Labels = labelsForManagedDatabase(ghost)
+	statefulSet.Spec.ServiceName = name
+	statefulSet.Spec.Selector.MatchLabels = selectorForManagedDatabase(ghost)
+	statefulSet.Spec.Template.Labels = labelsForManagedDatabase(ghost)
+	for i := range statefulSet.Spec.Template.Spec.Containers[0].Env {
+		env := &statefulSet.Spec.Template.Spec.Containers[0].Env[i]
+		if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+			env.ValueFrom.SecretKeyRef.Name = name
+		}
+	}
+	statefulSet.Spec.Template.Spec.Volumes[0].PersistentVolumeClaim.ClaimName = mysqlPvcNamePrefix + ghost.ObjectMeta.Name
+
+	return statefulSet, nil
+}
+
+// databaseInitContainers returns the init containers that hold Ghost back until
+// its managed database accepts connections.
+func databaseInitContainers(ghost *blogv2.Ghost) []corev1.Container {
+	if !ghost.Spec.Database.Managed {
+		return nil
+	}
+	db := databaseConnection(ghost)
+	return []corev1.Container{
+		{
+			Name:    "wait-for-mysql",
+			Image:   waitForMySQLImage,
+			Command: []string{"sh", "-c", fmt.Sprintf("until nc -z -w 2 %s %d; do echo waiting for mysql; sleep 2; done", db.Host, db.Port)},
+		},
+	}
+}
+
+// labelsForManagedDatabase returns the labels put on the managed database objects of the Ghost.
+func labelsForManagedDatabase(ghost *blogv2.Ghost) map[string]string {
+	labels := selectorForManagedDatabase(ghost)
+	labels[managedByLabel] = "ghost-operator"
+	return labels
+}
+
+// selectorForManagedDatabase returns the labels that select the MySQL pod of the Ghost.
+func selectorForManagedDatabase(ghost *blogv2.Ghost) map[string]string {
+	return map[string]string{
+		nameLabel:     "mysql",
+		instanceLabel: ghost.ObjectMeta.Name,
+	}
+}
+
+func generatePassword() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
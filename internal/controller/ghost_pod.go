@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	blogv2 "example.com/api/v2"
+)
+
+// ghostUserID is the uid and gid of the node user of the Ghost image
+const ghostUserID = int64(1000)
+
+// podSecurityContext returns the security context of the Ghost pods. By
+// default they run as the node user, which owns the content volume.
+func podSecurityContext(ghost *blogv2.Ghost) *corev1.PodSecurityContext {
+	if ghost.Spec.PodSecurityContext != nil {
+		return ghost.Spec.PodSecurityContext.DeepCopy()
+	}
+	runAsNonRoot := true
+	uid := ghostUserID
+	fsGroupChangePolicy := corev1.FSGroupChangeOnRootMismatch
+	return &corev1.PodSecurityContext{
+		RunAsNonRoot:        &runAsNonRoot,
+		RunAsUser:           &uid,
+		RunAsGroup:          &uid,
+		FSGroup:             &uid,
+		FSGroupChangePolicy: &fsGroupChangePolicy,
+		SeccompProfile:      &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+}
+
+// containerSecurityContext returns the security context of the Ghost
+// container. By default Ghost only writes to the content volume and /tmp.
+func containerSecurityContext(ghost *blogv2.Ghost) *corev1.SecurityContext {
+	if ghost.Spec.SecurityContext != nil {
+		return ghost.Spec.SecurityContext.DeepCopy()
+	}
+	readOnlyRootFilesystem := true
+	allowPrivilegeEscalation := false
+	return &corev1.SecurityContext{
+		ReadOnlyRootFilesystem:   &readOnlyRootFilesystem,
+		AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+}
+
+// applyPodPlacement sets the scheduling constraints of the Ghost on the pod spec
+func applyPodPlacement(ghost *blogv2.Ghost, podSpec *corev1.PodSpec) {
+	podSpec.NodeSelector = ghost.Spec.NodeSelector
+	podSpec.Tolerations = ghost.Spec.Tolerations
+	podSpec.Affinity = ghost.Spec.Affinity.DeepCopy()
+	podSpec.PriorityClassName = ghost.Spec.PriorityClassName
+
+	podSpec.TopologySpreadConstraints = nil
+	for _, constraint := range ghost.Spec.TopologySpreadConstraints {
+		constraint = *constraint.DeepCopy()
+		if constraint.LabelSelector == nil {
+			constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: selectorForGhost(ghost)}
+		}
+		podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, constraint)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	blogv2 "example.com/api/v2"
+)
+
+// ghostPort is the port Ghost listens on in the container
+const ghostPort = 2368
+
+// probePath answers once Ghost booted and its database is migrated
+const probePath = "/ghost/api/admin/site/"
+
+// Default thresholds of the probes. The startup probe gives a first boot 10
+// minutes to run the database migrations before the liveness probe kicks in.
+var (
+	defaultStartupProbe   = corev1.Probe{PeriodSeconds: 10, TimeoutSeconds: 5, FailureThreshold: 60}
+	defaultLivenessProbe  = corev1.Probe{PeriodSeconds: 20, TimeoutSeconds: 5, FailureThreshold: 3}
+	defaultReadinessProbe = corev1.Probe{PeriodSeconds: 10, TimeoutSeconds: 5, FailureThreshold: 3}
+)
+
+// containerProbes returns the startup, liveness and readiness probes of the
+// Ghost container. A disabled probe is nil.
+func containerProbes(ghost *blogv2.Ghost) (startup, liveness, readiness *corev1.Probe) {
+	probes := ghost.Spec.Probes
+	return probe(ghost, defaultStartupProbe, probes.Startup),
+		probe(ghost, defaultLivenessProbe, probes.Liveness),
+		probe(ghost, defaultReadinessProbe, probes.Readiness)
+}
+
+// probe builds a probe from its defaults and the thresholds set in spec
+func probe(ghost *blogv2.Ghost, defaults corev1.Probe, spec *blogv2.GhostProbeSpec) *corev1.Probe {
+	result := defaults.DeepCopy()
+	result.ProbeHandler = corev1.ProbeHandler{HTTPGet: probeHTTPGet(ghost)}
+	if spec == nil {
+		return result
+	}
+	if spec.Disabled {
+		return nil
+	}
+	if spec.InitialDelaySeconds != nil {
+		result.InitialDelaySeconds = *spec.InitialDelaySeconds
+	}
+	if spec.PeriodSeconds != nil {
+		result.PeriodSeconds = *spec.PeriodSeconds
+	}
+	if spec.TimeoutSeconds != nil {
+		result.TimeoutSeconds = *spec.TimeoutSeconds
+	}
+	if spec.FailureThreshold != nil {
+		result.FailureThreshold = *spec.FailureThreshold
+	}
+	return result
+}
+
+// probeHTTPGet requests the site through its public url when it is known, so
+// Ghost serves the request instead of redirecting it to the url.
+func probeHTTPGet(ghost *blogv2.Ghost) *corev1.HTTPGetAction {
+	action := &corev1.HTTPGetAction{
+		Path: probePath,
+		Port: intstr.FromInt32(ghostPort),
+	}
+	siteURL, err := url.Parse(ghostURL(ghost))
+	if err != nil || siteURL.Host == "" {
+		return action
+	}
+	action.Path = strings.TrimSuffix(siteURL.Path, "/") + probePath
+	action.HTTPHeaders = []corev1.HTTPHeader{{Name: "Host", Value: siteURL.Host}}
+	if siteURL.Scheme == "https" {
+		// Ghost redirects plain requests to https unless they came through TLS
+		action.HTTPHeaders = append(action.HTTPHeaders, corev1.HTTPHeader{Name: "X-Forwarded-Proto", Value: "https"})
+	}
+	return action
+}
@@ -0,0 +1,278 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	blogv2 "example.com/api/v2"
+)
+
+const (
+	restoreJobNamePrefix   = "ghost-restore-"
+	migrationJobNamePrefix = "ghost-migrate-"
+)
+
+// conditionRestoring is True while a GhostRestore holds the Ghost scaled down
+const conditionRestoring = "Restoring"
+
+// restoreContentPath is where the restore Job mounts the content volume
+const restoreContentPath = "/content"
+
+// ghostInstallPath is where the Ghost image installs Ghost
+const ghostInstallPath = "/var/lib/ghost"
+
+// restoreArchive is the archive a restore starts from
+type restoreArchive struct {
+	target   blogv2.BackupTarget
+	key      string
+	checksum string
+}
+
+// archiveFromBackup returns the archive stored by a GhostBackup
+func archiveFromBackup(backup *blogv2.GhostBackup) restoreArchive {
+	return restoreArchive{
+		target:   backup.Spec.Target,
+		key:      backupTargetKey(backup),
+		checksum: backup.Status.Checksum,
+	}
+}
+
+// archiveFromURL returns the archive at the url of a restore source
+func archiveFromURL(archive *blogv2.RestoreArchive) restoreArchive {
+	scheme, rest, _ := strings.Cut(archive.URL, "://")
+	name, key, _ := strings.Cut(rest, "/")
+	result := restoreArchive{key: key, checksum: archive.Checksum}
+	if scheme != "s3" {
+		result.target.PVC = &blogv2.PVCBackupTarget{ClaimName: name}
+		return result
+	}
+	result.target.S3 = &blogv2.S3BackupTarget{
+		Bucket:   name,
+		Endpoint: archive.Endpoint,
+		Region:   archive.Region,
+	}
+	if archive.CredentialsSecretRef != nil {
+		result.target.S3.CredentialsSecretRef = *archive.CredentialsSecretRef
+	}
+	return result
+}
+
+// generateRestoreJob returns the Job replacing the content and database of
+// the Ghost with the ones of the archive. Nothing is touched until the
+// archive has been fetched, verified and found to hold a database for the
+// client of the Ghost, so a bad archive leaves the Ghost as it was.
+func generateRestoreJob(restore *blogv2.GhostRestore, ghost *blogv2.Ghost, claimName string, archive restoreArchive) *batchv1.Job {
+	backoffLimit := int32(2)
+	uid := ghostUserID
+	contentMount := corev1.VolumeMount{Name: "content", MountPath: restoreContentPath}
+	archiveMount := corev1.VolumeMount{Name: "archive", MountPath: backupArchivePath}
+
+	volumes := []corev1.Volume{
+		{Name: "content", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claimName}}},
+		{Name: "archive", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+	}
+	volumes = append(volumes, backupTargetVolumes(archive.target)...)
+
+	fetch := backupTargetStep(archive.target, archive.key, "fetch",
+		`aws s3 cp --only-show-errors "$ARCHIVE_URL" `+backupArchivePath+`/backup.tar.gz`,
+		`cp "$ARCHIVE_PATH" `+backupArchivePath+`/backup.tar.gz`)
+	fetch.VolumeMounts = append(fetch.VolumeMounts, archiveMount)
+
+	databaseFile := "database.sqlite"
+	if ghost.Spec.DatabaseClient() == blogv2.DatabaseClientMySQL {
+		databaseFile = "database.sql"
+	}
+	extract := corev1.Container{
+		Name:    "extract",
+		Image:   backupToolsImage,
+		Command: []string{"sh", "-ec"},
+		Args: []string{`cd ` + backupArchivePath + `
+if [ -n "$CHECKSUM" ]; then
+  echo "${CHECKSUM#sha256:}  backup.tar.gz" | sha256sum -c -
+fi
+mkdir -p extracted
+tar -xzf backup.tar.gz -C extracted
+if [ ! -f "extracted/$DATABASE_FILE" ]; then
+  echo "The archive holds no $DATABASE_FILE, it was taken from a Ghost using another database client" >&2
+  exit 1
+fi
+find ` + restoreContentPath + ` -mindepth 1 -maxdepth 1 ! -name lost+found -exec rm -rf {} +
+cp -R extracted/content/. ` + restoreContentPath + `/`},
+		Env: []corev1.EnvVar{
+			{Name: "CHECKSUM", Value: archive.checksum},
+			{Name: "DATABASE_FILE", Value: databaseFile},
+		},
+		VolumeMounts: []corev1.VolumeMount{archiveMount, contentMount},
+	}
+
+	labels := labelsForGhost(ghost)
+	labels[nameLabel] = "ghost-restore"
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      boundedName(restoreJobNamePrefix, restore.Name),
+			Namespace: restore.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsUser:  &uid,
+						RunAsGroup: &uid,
+						FSGroup:    &uid,
+					},
+					// The managed database has to be up before anything is replaced
+					InitContainers: append(databaseInitContainers(ghost), fetch, extract),
+					Containers:     []corev1.Container{restoreLoadStep(ghost, archiveMount, contentMount)},
+					Volumes:        volumes,
+				},
+			},
+		},
+	}
+}
+
+// restoreLoadStep loads the database of the archive into the database of the Ghost
+func restoreLoadStep(ghost *blogv2.Ghost, archiveMount, contentMount corev1.VolumeMount) corev1.Container {
+	if ghost.Spec.DatabaseClient() != blogv2.DatabaseClientMySQL {
+		return corev1.Container{
+			Name:    "load-database",
+			Image:   backupToolsImage,
+			Command: []string{"sh", "-ec"},
+			Args: []string{`mkdir -p ` + restoreContentPath + `/data
+rm -rf ` + restoreContentPath + `/data/ghost.db-wal ` + restoreContentPath + `/data/ghost.db-shm ` + restoreContentPath + `/data/.ghost.db-litestream
+cp ` + backupArchivePath + `/extracted/database.sqlite ` + restoreContentPath + `/data/ghost.db`},
+			VolumeMounts: []corev1.VolumeMount{archiveMount, contentMount},
+		}
+	}
+	return corev1.Container{
+		Name:    "load-database",
+		Image:   backupMySQLImage,
+		Command: []string{"sh", "-ec"},
+		Args: []string{`mysql -h "$DB_HOST" -P "$DB_PORT" -u "$DB_USER" "$DB_NAME" < ` +
+			backupArchivePath + `/extracted/database.sql`},
+		Env:          mysqlClientEnv(ghost),
+		VolumeMounts: []corev1.VolumeMount{archiveMount},
+	}
+}
+
+// generateMigrationJob returns the Job running the database migrations of
+// the Ghost image. It runs the pod template of the Ghost Deployment, so the
+// migrations see the same image, configuration and content volume.
+func generateMigrationJob(ghost *blogv2.Ghost, claimName, name string) (*batchv1.Job, error) {
+	deploy, err := createDesiredDeployment(ghost, claimName, nil)
+	if err != nil {
+		return nil, err
+	}
+	backoffLimit := int32(2)
+
+	podSpec := deploy.Spec.Template.Spec
+	podSpec.RestartPolicy = corev1.RestartPolicyNever
+	// Sidecars like Litestream would keep the Job from completing
+	podSpec.Containers = podSpec.Containers[:1]
+	container := &podSpec.Containers[0]
+	container.Command = []string{"sh", "-ec"}
+	container.Args = []string{`cd ` + ghostInstallPath + `/current
+node_modules/.bin/knex-migrator-migrate --init --mgpath .`}
+	container.Ports = nil
+	container.StartupProbe = nil
+	container.LivenessProbe = nil
+	container.ReadinessProbe = nil
+
+	labels := labelsForGhost(ghost)
+	labels[nameLabel] = "ghost-migration"
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ghost.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}, nil
+}
+
+// restoreHoldsGhost reports whether the restore keeps its Ghost scaled down
+func restoreHoldsGhost(restore *blogv2.GhostRestore) bool {
+	switch restore.Status.Phase {
+	case blogv2.GhostRestorePhaseScalingDown, blogv2.GhostRestorePhaseRestoring, blogv2.GhostRestorePhaseMigrating:
+		return true
+	}
+	return false
+}
+
+// restoreInProgress reports whether the restore started and has not finished yet
+func restoreInProgress(restore *blogv2.GhostRestore) bool {
+	return restoreHoldsGhost(restore) || restore.Status.Phase == blogv2.GhostRestorePhaseScalingUp
+}
+
+// activeRestore returns the restore in progress for the Ghost, nil when there is none
+func activeRestore(ctx context.Context, c client.Reader, ghost *blogv2.Ghost) (*blogv2.GhostRestore, error) {
+	list := &blogv2.GhostRestoreList{}
+	if err := c.List(ctx, list, client.InNamespace(ghost.Namespace)); err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		if list.Items[i].Spec.GhostName == ghost.Name && restoreInProgress(&list.Items[i]) {
+			return &list.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// updateRestoreCondition reports whether a GhostRestore holds the Ghost
+// scaled down. The Deployment follows the condition.
+func (r *GhostReconciler) updateRestoreCondition(ctx context.Context, ghost *blogv2.Ghost) error {
+	restore, err := activeRestore(ctx, r.Client, ghost)
+	if err != nil {
+		return err
+	}
+	if restore == nil || !restoreHoldsGhost(restore) {
+		meta.RemoveStatusCondition(&ghost.Status.Conditions, conditionRestoring)
+		return nil
+	}
+	addCondition(ghost, conditionRestoring, metav1.ConditionTrue, string(restore.Status.Phase),
+		"GhostRestore "+restore.Name+" holds the Ghost scaled down")
+	return nil
+}
+
+// ghostForRestore maps a GhostRestore to the Ghost it restores, so the Ghost
+// scales down and back up as the restore goes.
+func ghostForRestore(ctx context.Context, obj client.Object) []reconcile.Request {
+	restore, ok := obj.(*blogv2.GhostRestore)
+	if !ok {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: restore.Namespace, Name: restore.Spec.GhostName}}}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	blogv2 "example.com/api/v2"
+)
+
+// Conditions describing the rollout of the Ghost Deployment
+const (
+	conditionAvailable   = "Available"
+	conditionProgressing = "Progressing"
+	conditionDegraded    = "Degraded"
+)
+
+// updateRolloutConditions sets the Available, Progressing and Degraded
+// conditions of the Ghost from the state of its Deployment, and reports
+// whether a rollout is still in flight.
+func updateRolloutConditions(ghost *blogv2.Ghost, deployment *appsv1.Deployment) bool {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+
+	// Available
+	if status.AvailableReplicas > 0 && status.AvailableReplicas >= desired {
+		addCondition(ghost, conditionAvailable, metav1.ConditionTrue, "MinimumReplicasAvailable",
+			fmt.Sprintf("%d of %d replicas available", status.AvailableReplicas, desired))
+	} else {
+		addCondition(ghost, conditionAvailable, metav1.ConditionFalse, "DeploymentUnavailable",
+			fmt.Sprintf("%d of %d replicas available", status.AvailableReplicas, desired))
+	}
+
+	// Degraded, once the Deployment gave up on the rollout
+	progressing := deploymentCondition(deployment, appsv1.DeploymentProgressing)
+	if progressing != nil && progressing.Status == corev1.ConditionFalse && progressing.Reason == "ProgressDeadlineExceeded" {
+		addCondition(ghost, conditionDegraded, metav1.ConditionTrue, "ProgressDeadlineExceeded", progressing.Message)
+		addCondition(ghost, conditionProgressing, metav1.ConditionFalse, "ProgressDeadlineExceeded", progressing.Message)
+		return false
+	}
+	addCondition(ghost, conditionDegraded, metav1.ConditionFalse, "AsExpected", "Deployment is progressing normally")
+
+	// Progressing, until every replica runs the current template and is available
+	var message string
+	switch {
+	case deployment.Generation > status.ObservedGeneration:
+		message = "Waiting for the Deployment spec update to be observed"
+	case status.UpdatedReplicas < desired:
+		message = fmt.Sprintf("%d of %d replicas updated", status.UpdatedReplicas, desired)
+	case status.Replicas > status.UpdatedReplicas:
+		message = fmt.Sprintf("%d old replicas pending termination", status.Replicas-status.UpdatedReplicas)
+	case status.AvailableReplicas < status.UpdatedReplicas:
+		message = fmt.Sprintf("%d of %d updated replicas available", status.AvailableReplicas, status.UpdatedReplicas)
+	}
+	if message != "" {
+		addCondition(ghost, conditionProgressing, metav1.ConditionTrue, "RolloutInProgress", message)
+		return true
+	}
+	addCondition(ghost, conditionProgressing, metav1.ConditionFalse, "RolloutComplete", "Deployment rollout is complete")
+	return false
+}
+
+func deploymentCondition(deployment *appsv1.Deployment, condType appsv1.DeploymentConditionType) *appsv1.DeploymentCondition {
+	for i := range deployment.Status.Conditions {
+		if deployment.Status.Conditions[i].Type == condType {
+			return &deployment.Status.Conditions[i]
+		}
+	}
+	return nil
+}
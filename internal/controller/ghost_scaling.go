@@ -0,0 +1,290 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	blogv2 "example.com/api/v2"
+)
+
+const hpaNamePrefix = "ghost-hpa-"
+
+// conditionScalingRestricted is True while the Ghost is held at one replica
+// because its pods could not share their data.
+const conditionScalingRestricted = "ScalingRestricted"
+
+// defaultTargetCPUUtilization of the autoscaler when the Ghost sets no target
+const defaultTargetCPUUtilization = int32(80)
+
+// requestedReplicas returns the most replicas the Ghost asks for, through
+// the autoscaler or else spec.replicas.
+func requestedReplicas(ghost *blogv2.Ghost) int32 {
+	if ghost.Spec.Autoscaling != nil {
+		return ghost.Spec.Autoscaling.MaxReplicas
+	}
+	if ghost.Spec.Replicas == nil {
+		return blogv2.DefaultReplicas
+	}
+	return *ghost.Spec.Replicas
+}
+
+// minReplicas returns the fewest replicas the autoscaler of the Ghost scales down to
+func minReplicas(ghost *blogv2.Ghost) *int32 {
+	replicas := int32(1)
+	if ghost.Spec.Autoscaling != nil && ghost.Spec.Autoscaling.MinReplicas != nil {
+		replicas = *ghost.Spec.Autoscaling.MinReplicas
+	}
+	return &replicas
+}
+
+// scalingRestriction explains why the Ghost cannot run more than one replica.
+// Every pod writes to the database and the uploaded images, so both have to
+// be shared: SQLite is a file only one Ghost may open, and a ReadWriteOnce
+// volume cannot be mounted on several nodes. The reason is empty when the
+// Ghost can scale.
+func (r *GhostReconciler) scalingRestriction(ctx context.Context, ghost *blogv2.Ghost) (string, string, error) {
+	if requestedReplicas(ghost) <= 1 {
+		return "", "", nil
+	}
+	if ghost.Spec.DatabaseClient() != blogv2.DatabaseClientMySQL {
+		return "DatabaseNotShared", "More than one replica requires a mysql database, sqlite3 cannot be shared between pods", nil
+	}
+	if ghost.Spec.Storage.Adapter != "" {
+		return "", "", nil
+	}
+	accessModes, err := r.contentAccessModes(ctx, ghost)
+	if err != nil {
+		return "", "", err
+	}
+	for _, mode := range accessModes {
+		if mode == corev1.ReadWriteMany {
+			return "", "", nil
+		}
+	}
+	return "StorageNotShared", "More than one replica requires ReadWriteMany content storage or a storage adapter", nil
+}
+
+// podsMayOverlap reports whether pods of two Deployments of the Ghost may run
+// at the same time: the database is MySQL and the content volume can be
+// mounted on several nodes.
+func (r *GhostReconciler) podsMayOverlap(ctx context.Context, ghost *blogv2.Ghost) (bool, error) {
+	if ghost.Spec.DatabaseClient() != blogv2.DatabaseClientMySQL {
+		return false, nil
+	}
+	accessModes, err := r.contentAccessModes(ctx, ghost)
+	if err != nil {
+		return false, err
+	}
+	for _, mode := range accessModes {
+		if mode == corev1.ReadWriteMany {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// contentAccessModes returns the access modes of the content volume, the
+// ones of the claim itself when it was brought by the user.
+func (r *GhostReconciler) contentAccessModes(ctx context.Context, ghost *blogv2.Ghost) ([]corev1.PersistentVolumeAccessMode, error) {
+	storage := ghost.Spec.Storage
+	if storage.ExistingClaim != "" {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: storage.ExistingClaim}, pvc)
+		if err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return pvc.Spec.AccessModes, nil
+	}
+	return ghost.Spec.StorageAccessModes(), nil
+}
+
+// autoscalingEnabled reports whether the replicas of the Ghost are left to its autoscaler
+func (r *GhostReconciler) autoscalingEnabled(ctx context.Context, ghost *blogv2.Ghost) (bool, error) {
+	if ghost.Spec.Autoscaling == nil {
+		return false, nil
+	}
+	reason, _, err := r.scalingRestriction(ctx, ghost)
+	return reason == "", err
+}
+
+// deploymentReplicas returns the replicas of the Deployment. They are left
+// unset for the autoscaler to manage when it is enabled, and are zero while
+// a restore or the migrations of an upgrade hold the Ghost.
+func (r *GhostReconciler) deploymentReplicas(ctx context.Context, ghost *blogv2.Ghost) (*int32, error) {
+	if meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionRestoring) || upgradeHoldsGhost(ghost) {
+		// No pod may hold the data a restore is replacing or a migration is changing
+		replicas := int32(0)
+		return &replicas, nil
+	}
+	reason, _, err := r.scalingRestriction(ctx, ghost)
+	if err != nil {
+		return nil, err
+	}
+	replicas := int32(1)
+	if reason == "" {
+		if ghost.Spec.Autoscaling != nil {
+			return nil, nil
+		}
+		replicas = requestedReplicas(ghost)
+	}
+	return &replicas, nil
+}
+
+// updateScalingCondition reports whether the Ghost is held at one replica
+func (r *GhostReconciler) updateScalingCondition(ctx context.Context, ghost *blogv2.Ghost) error {
+	reason, message, err := r.scalingRestriction(ctx, ghost)
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		meta.RemoveStatusCondition(&ghost.Status.Conditions, conditionScalingRestricted)
+		return nil
+	}
+	if !meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionScalingRestricted) {
+		r.recoder.Event(ghost, corev1.EventTypeWarning, "ScalingRestricted", message)
+	}
+	addCondition(ghost, conditionScalingRestricted, metav1.ConditionTrue, reason, message)
+	return nil
+}
+
+// addOrUpdateHPA makes the HorizontalPodAutoscaler of the Ghost match
+// spec.autoscaling, and removes it once the block is gone or the Ghost
+// cannot scale.
+func (r *GhostReconciler) addOrUpdateHPA(ctx context.Context, ghost *blogv2.Ghost, deploymentName string) error {
+	log := log.FromContext(ctx)
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.ObjectMeta.Namespace, Name: hpaNamePrefix + ghost.ObjectMeta.Name}, hpa)
+	if err != nil && client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	exists := err == nil
+	if exists && !metav1.IsControlledBy(hpa, ghost) {
+		return fmt.Errorf("horizontalpodautoscaler %s already exists and is not owned by Ghost %s", hpa.Name, ghost.Name)
+	}
+
+	enabled, err := r.autoscalingEnabled(ctx, ghost)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		if !exists {
+			return nil
+		}
+		if err := r.Delete(ctx, hpa); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "HPADeleted", "HorizontalPodAutoscaler deleted successfully")
+		log.Info("HorizontalPodAutoscaler deleted", "hpa", hpa.Name)
+		return nil
+	}
+
+	desiredHPA := generateDesiredHPA(ghost, deploymentName)
+	if !exists {
+		if err := controllerutil.SetControllerReference(ghost, desiredHPA, r.Scheme); err != nil {
+			return err
+		}
+		if err := r.Create(ctx, desiredHPA); err != nil {
+			return err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "HPACreated", "HorizontalPodAutoscaler created successfully")
+		log.Info("HorizontalPodAutoscaler created", "hpa", desiredHPA.Name)
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(desiredHPA.Spec, hpa.Spec) {
+		log.Info("HorizontalPodAutoscaler is up to date, no action required", "hpa", hpa.Name)
+		return nil
+	}
+	hpa.Spec = desiredHPA.Spec
+	if err := r.Update(ctx, hpa); err != nil {
+		return err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "HPAUpdated", "HorizontalPodAutoscaler updated successfully")
+	log.Info("HorizontalPodAutoscaler updated", "hpa", hpa.Name)
+	return nil
+}
+
+func generateDesiredHPA(ghost *blogv2.Ghost, deploymentName string) *autoscalingv2.HorizontalPodAutoscaler {
+	spec := ghost.Spec.Autoscaling
+
+	var metrics []autoscalingv2.MetricSpec
+	cpu := spec.TargetCPUUtilizationPercentage
+	if cpu == nil && spec.TargetMemoryUtilizationPercentage == nil {
+		target := defaultTargetCPUUtilization
+		cpu = &target
+	}
+	if cpu != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceCPU, *cpu))
+	}
+	if spec.TargetMemoryUtilizationPercentage != nil {
+		metrics = append(metrics, resourceMetric(corev1.ResourceMemory, *spec.TargetMemoryUtilizationPercentage))
+	}
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      hpaNamePrefix + ghost.ObjectMeta.Name,
+			Namespace: ghost.ObjectMeta.Namespace,
+			Labels:    labelsForGhost(ghost),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deploymentName,
+			},
+			MinReplicas: minReplicas(ghost),
+			MaxReplicas: spec.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+}
+
+// resourceMetric targets an average utilization of the requests of a resource
+func resourceMetric(name corev1.ResourceName, utilization int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.ResourceMetricSourceType,
+		Resource: &autoscalingv2.ResourceMetricSource{
+			Name: name,
+			Target: autoscalingv2.MetricTarget{
+				Type:               autoscalingv2.UtilizationMetricType,
+				AverageUtilization: &utilization,
+			},
+		},
+	}
+}
+
+// updateScaleStatus reports the replicas and pod selector of the Deployment,
+// which back the scale subresource of the Ghost.
+func updateScaleStatus(ghost *blogv2.Ghost, deployment *appsv1.Deployment) {
+	ghost.Status.Replicas = deployment.Status.Replicas
+	ghost.Status.Selector = ""
+	if deployment.Spec.Selector != nil {
+		ghost.Status.Selector = metav1.FormatLabelSelector(deployment.Spec.Selector)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	blogv2 "example.com/api/v2"
+)
+
+// conditionStorageResizing is True while the content volume is being expanded
+const conditionStorageResizing = "StorageResizing"
+
+// resizePvc grows the content PVC to the size requested by the Ghost when its
+// storage class allows volume expansion, and reports whether a resize is
+// still pending. Shrinking is refused, PVCs cannot be made smaller.
+func (r *GhostReconciler) resizePvc(ctx context.Context, ghost *blogv2.Ghost, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	log := log.FromContext(ctx)
+	desired := ghost.Spec.StorageSize()
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+
+	switch desired.Cmp(requested) {
+	case -1:
+		message := fmt.Sprintf("Shrinking PVC %s from %s to %s is not supported", pvc.Name, requested.String(), desired.String())
+		addCondition(ghost, conditionStorageResizing, metav1.ConditionFalse, "ShrinkNotSupported", message)
+		r.recoder.Event(ghost, corev1.EventTypeWarning, "PVCShrinkRejected", message)
+		return false, nil
+	case 1:
+		expandable, err := r.allowsVolumeExpansion(ctx, pvc)
+		if err != nil {
+			return false, err
+		}
+		if !expandable {
+			message := fmt.Sprintf("Storage class of PVC %s does not allow volume expansion", pvc.Name)
+			addCondition(ghost, conditionStorageResizing, metav1.ConditionFalse, "ExpansionNotAllowed", message)
+			r.recoder.Event(ghost, corev1.EventTypeWarning, "PVCExpansionNotAllowed", message)
+			return false, nil
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = desired
+		if err := r.Update(ctx, pvc); err != nil {
+			return false, err
+		}
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "PVCResizing", fmt.Sprintf("PVC %s is being expanded to %s", pvc.Name, desired.String()))
+		log.Info("PVC expansion requested", "pvc", pvc.Name, "size", desired.String())
+	}
+
+	// The request matches, wait for the volume to catch up
+	if reason, pending := pvcResizePending(pvc); pending {
+		addCondition(ghost, conditionStorageResizing, metav1.ConditionTrue, reason,
+			fmt.Sprintf("PVC %s is being expanded to %s", pvc.Name, desired.String()))
+		return true, nil
+	}
+	meta.RemoveStatusCondition(&ghost.Status.Conditions, conditionStorageResizing)
+	return false, nil
+}
+
+// allowsVolumeExpansion reports whether the storage class of the PVC allows it to grow
+func (r *GhostReconciler) allowsVolumeExpansion(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+	storageClass := &storagev1.StorageClass{}
+	if err := r.Get(ctx, client.ObjectKey{Name: *pvc.Spec.StorageClassName}, storageClass); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
+}
+
+// pvcResizePending reports whether the volume is still smaller than requested,
+// along with the stage the resize is in.
+func pvcResizePending(pvc *corev1.PersistentVolumeClaim) (string, bool) {
+	for _, cond := range pvc.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case corev1.PersistentVolumeClaimResizing:
+			return "Resizing", true
+		case corev1.PersistentVolumeClaimFileSystemResizePending:
+			return "FileSystemResizePending", true
+		}
+	}
+	requested := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity, bound := pvc.Status.Capacity[corev1.ResourceStorage]
+	if bound && capacity.Cmp(requested) < 0 {
+		return "Resizing", true
+	}
+	return "", false
+}
@@ -0,0 +1,440 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	blogv2 "example.com/api/v2"
+)
+
+// conditionUpgradeBlocked is True while an upgrade waits for a backup target
+const conditionUpgradeBlocked = "UpgradeBlocked"
+
+// defaultUpgradeProgressDeadline is how long the new pods of an upgrade have
+// to become available when the Ghost sets no deadline
+const defaultUpgradeProgressDeadline = 10 * time.Minute
+
+// upgradeFinished reports whether the upgrade succeeded, was rolled back or failed
+func upgradeFinished(upgrade *blogv2.GhostUpgradeStatus) bool {
+	switch upgrade.Phase {
+	case blogv2.GhostUpgradePhaseSucceeded, blogv2.GhostUpgradePhaseRolledBack, blogv2.GhostUpgradePhaseFailed:
+		return true
+	}
+	return false
+}
+
+// upgradeHoldsGhost reports whether an upgrade keeps the Ghost scaled down,
+// so no pod of the previous image runs while the database is migrated
+func upgradeHoldsGhost(ghost *blogv2.Ghost) bool {
+	upgrade := ghost.Status.Upgrade
+	return upgrade != nil && upgrade.Phase == blogv2.GhostUpgradePhaseMigrating
+}
+
+// deploymentImage returns the image the Ghost pods run. An upgrade runs the
+// previous image until the database is migrated for the new one, and again
+// once it is rolled back or failed, until the spec asks for another image.
+func deploymentImage(ghost *blogv2.Ghost) string {
+	desired := ghost.Spec.ImageReference()
+	upgrade := ghost.Status.Upgrade
+	if upgrade == nil {
+		return desired
+	}
+	switch upgrade.Phase {
+	case blogv2.GhostUpgradePhaseBlocked, blogv2.GhostUpgradePhaseBackingUp,
+		blogv2.GhostUpgradePhaseMigrating, blogv2.GhostUpgradePhaseRollingBack:
+		return upgrade.FromImage
+	case blogv2.GhostUpgradePhaseRollingOut:
+		return upgrade.ToImage
+	case blogv2.GhostUpgradePhaseRolledBack, blogv2.GhostUpgradePhaseFailed:
+		if upgrade.ToImage == desired {
+			return upgrade.FromImage
+		}
+	}
+	return desired
+}
+
+// upgradeBackupTarget returns where the pre-upgrade backup is stored, nil
+// when the Ghost has no backup target
+func upgradeBackupTarget(ghost *blogv2.Ghost) *blogv2.BackupTarget {
+	if ghost.Spec.Upgrade != nil && ghost.Spec.Upgrade.BackupTarget != nil {
+		return ghost.Spec.Upgrade.BackupTarget
+	}
+	if ghost.Spec.Backup != nil {
+		return &ghost.Spec.Backup.Target
+	}
+	return nil
+}
+
+// upgradeProgressDeadline returns how long the new pods have to become available
+func upgradeProgressDeadline(ghost *blogv2.Ghost) time.Duration {
+	if ghost.Spec.Upgrade != nil && ghost.Spec.Upgrade.ProgressDeadline != nil {
+		return ghost.Spec.Upgrade.ProgressDeadline.Duration
+	}
+	return defaultUpgradeProgressDeadline
+}
+
+// deploymentRolledOut reports whether every replica of the Deployment runs
+// its current template and is available
+func deploymentRolledOut(deployment *appsv1.Deployment) bool {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas >= desired &&
+		status.Replicas == status.UpdatedReplicas &&
+		status.AvailableReplicas >= desired
+}
+
+// reconcileUpgrade moves the image change of the Ghost one step further and
+// reports whether it is still in flight. It runs before the Deployment is
+// updated, which then runs the image deploymentImage picks for the phase.
+func (r *GhostReconciler) reconcileUpgrade(ctx context.Context, ghost *blogv2.Ghost) (bool, error) {
+	upgrade := ghost.Status.Upgrade
+	if upgrade == nil || upgradeFinished(upgrade) || upgrade.Phase == blogv2.GhostUpgradePhaseBlocked {
+		started, err := r.startUpgrade(ctx, ghost)
+		updateUpgradeBlockedCondition(ghost)
+		if err != nil || !started {
+			return false, err
+		}
+	}
+
+	var err error
+	switch ghost.Status.Upgrade.Phase {
+	case blogv2.GhostUpgradePhaseBackingUp:
+		err = r.migrateWhenBackedUp(ctx, ghost)
+	case blogv2.GhostUpgradePhaseMigrating:
+		err = r.rollOutWhenMigrated(ctx, ghost)
+	case blogv2.GhostUpgradePhaseRollingOut:
+		err = r.succeedWhenRolledOut(ctx, ghost)
+	case blogv2.GhostUpgradePhaseRollingBack:
+		err = r.completeRollbackWhenRestored(ctx, ghost)
+	}
+	return !upgradeFinished(ghost.Status.Upgrade), err
+}
+
+// startUpgrade starts an upgrade when the Deployment runs another image than
+// the Ghost asks for. Without a backup target the upgrade is blocked until
+// one is set, unless the Ghost skips the backup. A rolled back or failed
+// upgrade is not retried until the Ghost asks for another image.
+func (r *GhostReconciler) startUpgrade(ctx context.Context, ghost *blogv2.Ghost) (bool, error) {
+	deploymentName := ghost.Status.DeploymentName
+	if deploymentName == "" {
+		deploymentName = deploymentNamePrefix + ghost.Name
+	}
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ghost.Namespace, Name: deploymentName}, deployment); err != nil {
+		// A new Ghost starts on its image right away
+		return false, client.IgnoreNotFound(err)
+	}
+	current := deployment.Spec.Template.Spec.Containers[0].Image
+	desired := ghost.Spec.ImageReference()
+	last := ghost.Status.Upgrade
+	blocked := last != nil && last.Phase == blogv2.GhostUpgradePhaseBlocked
+	if current == desired {
+		if blocked {
+			ghost.Status.Upgrade = nil
+		}
+		return false, nil
+	}
+	if last != nil && !blocked && last.ToImage == desired {
+		return false, nil
+	}
+
+	// Go back to the exact image recorded for the pods, digest included
+	from := current
+	if recorded := ghost.Status.Image; strings.SplitN(recorded, "@", 2)[0] == strings.SplitN(current, "@", 2)[0] {
+		from = recorded
+	}
+
+	// Without a backup, a rollback would leave the database half migrated
+	target := upgradeBackupTarget(ghost)
+	skipBackup := ghost.Spec.Upgrade != nil && ghost.Spec.Upgrade.SkipBackup
+	if target == nil && !skipBackup {
+		if !blocked || last.ToImage != desired {
+			r.recoder.Event(ghost, corev1.EventTypeWarning, "UpgradeBlocked",
+				"No backup target is set, the Ghost keeps "+from)
+		}
+		ghost.Status.Upgrade = &blogv2.GhostUpgradeStatus{
+			Phase:              blogv2.GhostUpgradePhaseBlocked,
+			FromImage:          from,
+			ToImage:            desired,
+			ObservedGeneration: ghost.Generation,
+			Message:            "Set a backup target, or spec.upgrade.skipBackup, to upgrade to " + desired,
+		}
+		return true, nil
+	}
+
+	now := metav1.Now()
+	ghost.Status.Upgrade = &blogv2.GhostUpgradeStatus{
+		Phase:              blogv2.GhostUpgradePhaseBackingUp,
+		FromImage:          from,
+		ToImage:            desired,
+		ObservedGeneration: ghost.Generation,
+		StartTime:          &now,
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "UpgradeStarted", "Upgrading from "+from+" to "+desired)
+	log.FromContext(ctx).Info("Upgrade started", "from", from, "to", desired)
+
+	if target == nil {
+		r.recoder.Event(ghost, corev1.EventTypeWarning, "UpgradeNotBackedUp",
+			"The backup is skipped, a rollback only reverts the image")
+		r.startMigration(ghost)
+		return true, nil
+	}
+
+	// Named after the generation asking for the image, so starting over after
+	// the status could not be written picks the same backup up again
+	backup := &blogv2.GhostBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-upgrade-%d", ghost.Name, ghost.Generation),
+			Namespace: ghost.Namespace,
+			Labels:    map[string]string{backupGhostLabel: ghost.Name},
+			// The archive goes along with the backup once it is deleted
+			Finalizers: []string{backupArchiveFinalizer},
+		},
+		Spec: blogv2.GhostBackupSpec{
+			GhostName: ghost.Name,
+			Target:    *target.DeepCopy(),
+		},
+	}
+	if err := r.Create(ctx, backup); err != nil && !errors.IsAlreadyExists(err) {
+		return false, err
+	}
+	ghost.Status.Upgrade.BackupName = backup.Name
+	ghost.Status.Upgrade.Message = "Waiting for GhostBackup " + backup.Name + " to succeed"
+	return true, nil
+}
+
+// updateUpgradeBlockedCondition raises UpgradeBlocked while an upgrade waits
+// for a backup target, and clears it otherwise
+func updateUpgradeBlockedCondition(ghost *blogv2.Ghost) {
+	upgrade := ghost.Status.Upgrade
+	if upgrade == nil || upgrade.Phase != blogv2.GhostUpgradePhaseBlocked {
+		meta.RemoveStatusCondition(&ghost.Status.Conditions, conditionUpgradeBlocked)
+		return
+	}
+	addCondition(ghost, conditionUpgradeBlocked, metav1.ConditionTrue, "NoBackupTarget", upgrade.Message)
+}
+
+// migrateWhenBackedUp moves on to the migrations once the pre-upgrade backup
+// succeeded. The Ghost keeps the previous image when the backup failed.
+func (r *GhostReconciler) migrateWhenBackedUp(ctx context.Context, ghost *blogv2.Ghost) error {
+	upgrade := ghost.Status.Upgrade
+	backup := &blogv2.GhostBackup{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.Namespace, Name: upgrade.BackupName}, backup)
+	if errors.IsNotFound(err) {
+		r.failUpgrade(ctx, ghost, "GhostBackup "+upgrade.BackupName+" was deleted")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch backup.Status.Phase {
+	case blogv2.GhostBackupPhaseSucceeded:
+		r.startMigration(ghost)
+	case blogv2.GhostBackupPhaseFailed:
+		r.failUpgrade(ctx, ghost, "GhostBackup "+backup.Name+" failed, the Ghost keeps "+upgrade.FromImage)
+	}
+	return nil
+}
+
+// startMigration scales the Ghost down for the migration Job
+func (r *GhostReconciler) startMigration(ghost *blogv2.Ghost) {
+	ghost.Status.Upgrade.Phase = blogv2.GhostUpgradePhaseMigrating
+	ghost.Status.Upgrade.Message = "Waiting for the Ghost pods to stop"
+}
+
+// rollOutWhenMigrated runs the migrations of the new image once the Ghost
+// pods are gone, and rolls the new image out once they completed. A failed
+// migration is rolled back.
+func (r *GhostReconciler) rollOutWhenMigrated(ctx context.Context, ghost *blogv2.Ghost) error {
+	upgrade := ghost.Status.Upgrade
+	if upgrade.MigrationJobName == "" {
+		pods := &corev1.PodList{}
+		if err := r.List(ctx, pods, client.InNamespace(ghost.Namespace), client.MatchingLabels(selectorForGhost(ghost))); err != nil {
+			return err
+		}
+		if len(pods.Items) > 0 {
+			return nil
+		}
+		return r.createMigrationJob(ctx, ghost)
+	}
+
+	job := &batchv1.Job{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.Namespace, Name: upgrade.MigrationJobName}, job)
+	if errors.IsNotFound(err) {
+		return r.rollBackUpgrade(ctx, ghost, "Migration Job "+upgrade.MigrationJobName+" was deleted")
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case jobHasCondition(job, batchv1.JobComplete):
+		now := metav1.Now()
+		upgrade.Phase = blogv2.GhostUpgradePhaseRollingOut
+		upgrade.Message = "Waiting for the pods of " + upgrade.ToImage + " to become available"
+		upgrade.RolloutStartTime = &now
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "UpgradeRollingOut", "Rolling out "+upgrade.ToImage)
+	case jobHasCondition(job, batchv1.JobFailed):
+		return r.rollBackUpgrade(ctx, ghost, "Migration Job "+job.Name+" failed: "+jobConditionMessage(job, batchv1.JobFailed))
+	}
+	return nil
+}
+
+// createMigrationJob creates the Job running the migrations of the new image
+func (r *GhostReconciler) createMigrationJob(ctx context.Context, ghost *blogv2.Ghost) error {
+	upgrade := ghost.Status.Upgrade
+	claimName, err := r.dataClaimName(ctx, ghost)
+	if err != nil {
+		return err
+	}
+	// Named after the generation the upgrade started for, like the backup
+	name := boundedName(migrationJobNamePrefix, fmt.Sprintf("%s-%d", ghost.Name, upgrade.ObservedGeneration))
+	job, err := generateMigrationJob(ghost, claimName, name)
+	if err != nil {
+		return err
+	}
+	job.Spec.Template.Spec.Containers[0].Image = upgrade.ToImage
+	if err := controllerutil.SetControllerReference(ghost, job, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	r.recoder.Event(ghost, corev1.EventTypeNormal, "UpgradeMigrating", "Migration Job "+job.Name+" created")
+	log.FromContext(ctx).Info("Upgrade migration Job created", "job", job.Name)
+
+	upgrade.MigrationJobName = job.Name
+	upgrade.Message = "Migration Job " + job.Name + " is running"
+	return nil
+}
+
+// succeedWhenRolledOut completes the upgrade once the pods of the new image
+// are available, and rolls it back when they are not within the deadline.
+func (r *GhostReconciler) succeedWhenRolledOut(ctx context.Context, ghost *blogv2.Ghost) error {
+	upgrade := ghost.Status.Upgrade
+	deployment := &appsv1.Deployment{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.Namespace, Name: ghost.Status.DeploymentName}, deployment)
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	if err == nil && deployment.Spec.Template.Spec.Containers[0].Image == upgrade.ToImage && deploymentRolledOut(deployment) {
+		now := metav1.Now()
+		upgrade.Phase = blogv2.GhostUpgradePhaseSucceeded
+		upgrade.Message = "Ghost runs " + upgrade.ToImage
+		upgrade.CompletionTime = &now
+		r.recoder.Event(ghost, corev1.EventTypeNormal, "UpgradeSucceeded", upgrade.Message)
+		log.FromContext(ctx).Info("Upgrade succeeded", "image", upgrade.ToImage)
+		return nil
+	}
+
+	deadline := upgradeProgressDeadline(ghost)
+	if time.Since(upgrade.RolloutStartTime.Time) > deadline {
+		return r.rollBackUpgrade(ctx, ghost, fmt.Sprintf("The pods of %s did not become available within %s", upgrade.ToImage, deadline))
+	}
+	return nil
+}
+
+// rollBackUpgrade goes back to the previous image and restores the
+// pre-upgrade backup, which may have been migrated already
+func (r *GhostReconciler) rollBackUpgrade(ctx context.Context, ghost *blogv2.Ghost, reason string) error {
+	upgrade := ghost.Status.Upgrade
+	r.recoder.Event(ghost, corev1.EventTypeWarning, "UpgradeRollingBack", reason)
+	log.FromContext(ctx).Info("Rolling back upgrade", "reason", reason, "image", upgrade.FromImage)
+
+	if upgrade.BackupName == "" {
+		now := metav1.Now()
+		upgrade.Phase = blogv2.GhostUpgradePhaseRolledBack
+		upgrade.Message = reason + ", reverted to " + upgrade.FromImage + " without restoring the database, no backup was taken"
+		upgrade.CompletionTime = &now
+		r.recoder.Event(ghost, corev1.EventTypeWarning, "UpgradeRolledBack", upgrade.Message)
+		return nil
+	}
+
+	restore := &blogv2.GhostRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-rollback-%d", ghost.Name, upgrade.ObservedGeneration),
+			Namespace: ghost.Namespace,
+			Labels:    map[string]string{backupGhostLabel: ghost.Name},
+		},
+		Spec: blogv2.GhostRestoreSpec{
+			GhostName: ghost.Name,
+			Source:    blogv2.RestoreSource{BackupName: upgrade.BackupName},
+		},
+	}
+	if err := r.Create(ctx, restore); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	upgrade.Phase = blogv2.GhostUpgradePhaseRollingBack
+	upgrade.Message = reason + ", restoring GhostBackup " + upgrade.BackupName + " with " + upgrade.FromImage
+	upgrade.RestoreName = restore.Name
+	return nil
+}
+
+// completeRollbackWhenRestored finishes the rollback once its restore is done
+func (r *GhostReconciler) completeRollbackWhenRestored(ctx context.Context, ghost *blogv2.Ghost) error {
+	upgrade := ghost.Status.Upgrade
+	restore := &blogv2.GhostRestore{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: ghost.Namespace, Name: upgrade.RestoreName}, restore)
+	if errors.IsNotFound(err) {
+		r.failUpgrade(ctx, ghost, "GhostRestore "+upgrade.RestoreName+" was deleted before the rollback completed")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	switch restore.Status.Phase {
+	case blogv2.GhostRestorePhaseSucceeded:
+		now := metav1.Now()
+		upgrade.Phase = blogv2.GhostUpgradePhaseRolledBack
+		upgrade.Message = "Ghost runs " + upgrade.FromImage + " with the database of GhostBackup " + upgrade.BackupName
+		upgrade.CompletionTime = &now
+		r.recoder.Event(ghost, corev1.EventTypeWarning, "UpgradeRolledBack", upgrade.Message)
+		log.FromContext(ctx).Info("Upgrade rolled back", "image", upgrade.FromImage)
+	case blogv2.GhostRestorePhaseFailed:
+		r.failUpgrade(ctx, ghost, "GhostRestore "+restore.Name+" failed: "+restore.Status.Message)
+	}
+	return nil
+}
+
+// failUpgrade marks an upgrade that can neither be completed nor rolled back
+// as failed. The Ghost keeps the previous image.
+func (r *GhostReconciler) failUpgrade(ctx context.Context, ghost *blogv2.Ghost, message string) {
+	now := metav1.Now()
+	upgrade := ghost.Status.Upgrade
+	upgrade.Phase = blogv2.GhostUpgradePhaseFailed
+	upgrade.Message = message
+	upgrade.CompletionTime = &now
+	r.recoder.Event(ghost, corev1.EventTypeWarning, "UpgradeFailed", message)
+	log.FromContext(ctx).Info("Upgrade failed", "message", message)
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	blogv2 "example.com/api/v2"
+)
+
+// jobNameLabel is set by the Job controller on the pods of a Job
+const jobNameLabel = "batch.kubernetes.io/job-name"
+
+// GhostBackupReconciler reconciles a GhostBackup object
+type GhostBackupReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	recoder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=blog.example.com,resources=ghostbackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=blog.example.com,resources=ghostbackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=blog.example.com,resources=ghostbackups/finalizers,verbs=update
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile runs a Job archiving the Ghost of a GhostBackup and records the
+// outcome in its status. A finished backup is left alone, it only records
+// where its archive is.
+func (r *GhostBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	backup := &blogv2.GhostBackup{}
+	if err := r.Get(ctx, req.NamespacedName, backup); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !backup.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.deleteArchive(ctx, backup)
+	}
+	if backupFinished(backup) {
+		return ctrl.Result{}, nil
+	}
+
+	job := &batchv1.Job{}
+	jobName := boundedName(backupJobNamePrefix, backup.Name)
+	err := r.Get(ctx, client.ObjectKey{Namespace: backup.Namespace, Name: jobName}, job)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if errors.IsNotFound(err) {
+		return ctrl.Result{}, r.startBackup(ctx, backup)
+	}
+
+	// The status write after creating the Job may have failed
+	recorded := backup.Status.JobName != ""
+	if !recorded {
+		recordBackupJob(backup, job)
+	}
+
+	switch {
+	case jobHasCondition(job, batchv1.JobComplete):
+		result, err := r.backupJobResult(ctx, job)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		backup.Status.Phase = blogv2.GhostBackupPhaseSucceeded
+		backup.Status.Message = "Archive stored in " + backup.Status.Location
+		if result != nil {
+			backup.Status.Size = result.Size
+			backup.Status.Checksum = result.Checksum
+		}
+		backup.Status.CompletionTime = jobCompletionTime(job)
+		r.recoder.Event(backup, corev1.EventTypeNormal, "BackupSucceeded", backup.Status.Message)
+		log.Info("Backup succeeded", "backup", backup.Name, "location", backup.Status.Location)
+	case jobHasCondition(job, batchv1.JobFailed):
+		backup.Status.Phase = blogv2.GhostBackupPhaseFailed
+		backup.Status.Message = "Backup Job failed: " + jobConditionMessage(job, batchv1.JobFailed)
+		backup.Status.CompletionTime = jobCompletionTime(job)
+		r.recoder.Event(backup, corev1.EventTypeWarning, "BackupFailed", backup.Status.Message)
+		log.Info("Backup failed", "backup", backup.Name, "message", backup.Status.Message)
+	default:
+		if backup.Status.Phase == blogv2.GhostBackupPhaseRunning && recorded {
+			return ctrl.Result{}, nil
+		}
+		backup.Status.Phase = blogv2.GhostBackupPhaseRunning
+		backup.Status.Message = "Backup Job " + job.Name + " is running"
+	}
+	return ctrl.Result{}, r.Status().Update(ctx, backup)
+}
+
+// startBackup creates the Job taking the backup
+func (r *GhostBackupReconciler) startBackup(ctx context.Context, backup *blogv2.GhostBackup) error {
+	log := log.FromContext(ctx)
+
+	ghost := &blogv2.Ghost{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: backup.Namespace, Name: backup.Spec.GhostName}, ghost)
+	if errors.IsNotFound(err) {
+		return r.failBackup(ctx, backup, fmt.Sprintf("Ghost %s not found", backup.Spec.GhostName))
+	}
+	if err != nil {
+		return err
+	}
+
+	claimName, err := lookupDataClaimName(ctx, r.Client, ghost)
+	if err != nil {
+		return err
+	}
+	nextToGhost, err := ghostPodsHoldVolume(ctx, r.Client, ghost, claimName)
+	if err != nil {
+		return err
+	}
+	job := generateBackupJob(backup, ghost, claimName, nextToGhost)
+	if err := controllerutil.SetControllerReference(backup, job, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, job); err != nil {
+		return err
+	}
+	r.recoder.Event(backup, corev1.EventTypeNormal, "BackupStarted", "Backup Job "+job.Name+" created")
+	log.Info("Backup Job created", "backup", backup.Name, "job", job.Name)
+
+	backup.Status.Phase = blogv2.GhostBackupPhaseRunning
+	backup.Status.Message = "Backup Job " + job.Name + " is running"
+	recordBackupJob(backup, job)
+	return r.Status().Update(ctx, backup)
+}
+
+// recordBackupJob records the Job taking the backup, when it started and
+// where it stores the archive
+func recordBackupJob(backup *blogv2.GhostBackup, job *batchv1.Job) {
+	startTime := job.CreationTimestamp
+	backup.Status.JobName = job.Name
+	backup.Status.Location = backupLocation(backup)
+	backup.Status.StartTime = &startTime
+}
+
+// deleteArchive runs a Job deleting the archive of an expired backup from its
+// target and releases the backup once it is done. An archive that cannot be
+// deleted is left behind rather than blocking the backup forever.
+func (r *GhostBackupReconciler) deleteArchive(ctx context.Context, backup *blogv2.GhostBackup) error {
+	log := log.FromContext(ctx)
+	if !controllerutil.ContainsFinalizer(backup, backupArchiveFinalizer) {
+		return nil
+	}
+
+	if backup.Status.Phase == blogv2.GhostBackupPhaseSucceeded {
+		job := &batchv1.Job{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: backup.Namespace, Name: boundedName(pruneJobNamePrefix, backup.Name)}, job)
+		if errors.IsNotFound(err) {
+			job = generatePruneJob(backup)
+			if err := controllerutil.SetControllerReference(backup, job, r.Scheme); err != nil {
+				return err
+			}
+			if err := r.Create(ctx, job); err != nil {
+				return err
+			}
+			log.Info("Archive prune Job created", "backup", backup.Name, "job", job.Name)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case jobHasCondition(job, batchv1.JobComplete):
+			r.recoder.Event(backup, corev1.EventTypeNormal, "ArchiveDeleted", "Archive "+backup.Status.Location+" deleted")
+		case jobHasCondition(job, batchv1.JobFailed):
+			r.recoder.Event(backup, corev1.EventTypeWarning, "ArchiveDeleteFailed",
+				"Archive "+backup.Status.Location+" could not be deleted: "+jobConditionMessage(job, batchv1.JobFailed))
+		default:
+			return nil
+		}
+	}
+
+	controllerutil.RemoveFinalizer(backup, backupArchiveFinalizer)
+	return r.Update(ctx, backup)
+}
+
+// failBackup marks a backup that cannot be taken as failed
+func (r *GhostBackupReconciler) failBackup(ctx context.Context, backup *blogv2.GhostBackup, message string) error {
+	now := metav1.Now()
+	backup.Status.Phase = blogv2.GhostBackupPhaseFailed
+	backup.Status.Message = message
+	backup.Status.CompletionTime = &now
+	r.recoder.Event(backup, corev1.EventTypeWarning, "BackupFailed", message)
+	return r.Status().Update(ctx, backup)
+}
+
+// backupJobResult reads the size and checksum of the archive from the
+// termination message of the upload step. It is nil when the pod is gone.
+func (r *GhostBackupReconciler) backupJobResult(ctx context.Context, job *batchv1.Job) (*backupResult, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(job.Namespace), client.MatchingLabels{jobNameLabel: job.Name}); err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != backupUploadContainer || status.State.Terminated == nil {
+				continue
+			}
+			result, err := parseBackupResult(status.State.Terminated.Message)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Failed to parse the backup result", "pod", pod.Name)
+				continue
+			}
+			return &result, nil
+		}
+	}
+	return nil, nil
+}
+
+// backupFinished reports whether the backup succeeded or failed
+func backupFinished(backup *blogv2.GhostBackup) bool {
+	return backup.Status.Phase == blogv2.GhostBackupPhaseSucceeded || backup.Status.Phase == blogv2.GhostBackupPhaseFailed
+}
+
+// jobHasCondition reports whether the condition of the Job is True
+func jobHasCondition(job *batchv1.Job, condType batchv1.JobConditionType) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == condType && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// jobConditionMessage returns the message of a condition of the Job
+func jobConditionMessage(job *batchv1.Job, condType batchv1.JobConditionType) string {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == condType {
+			return cond.Message
+		}
+	}
+	return ""
+}
+
+// jobCompletionTime returns when the Job finished, or now when it does not tell
+func jobCompletionTime(job *batchv1.Job) *metav1.Time {
+	if job.Status.CompletionTime != nil {
+		return job.Status.CompletionTime.DeepCopy()
+	}
+	now := metav1.Now()
+	return &now
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GhostBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recoder = mgr.GetEventRecorderFor("ghostbackup-controller")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&blogv2.GhostBackup{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
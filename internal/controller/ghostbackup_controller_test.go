@@ -0,0 +1,262 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	blogv2 "example.com/api/v2"
+)
+
+var _ = Describe("GhostBackup Controller", func() {
+	Context("When backing up a Ghost to a PVC", func() {
+		const ghostName = "backed-up-blog"
+		const resourceName = "backed-up-blog-manual"
+		const checksum = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			ghost := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: ghostName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, ghost)).To(Succeed())
+			backup := &blogv2.GhostBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostBackupSpec{
+					GhostName: ghostName,
+					Target: blogv2.BackupTarget{
+						PVC: &blogv2.PVCBackupTarget{ClaimName: "backups", Path: "blogs"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, backup)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			backup := &blogv2.GhostBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, backup)).To(Succeed())
+			deleteGhost(ctx, types.NamespacedName{Name: ghostName, Namespace: "default"})
+		})
+
+		It("should run a backup Job and record the archive it stored", func() {
+			controllerReconciler := &GhostBackupReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			backup := &blogv2.GhostBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			Expect(backup.Status.Phase).To(Equal(blogv2.GhostBackupPhaseRunning))
+			Expect(backup.Status.Location).To(Equal("pvc://backups/blogs/default/" + ghostName + "/" + resourceName + ".tar.gz"))
+
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backup.Status.JobName, Namespace: "default"}, job)).To(Succeed())
+			podSpec := job.Spec.Template.Spec
+			Expect(podSpec.InitContainers[0].Image).To(Equal(backupSQLiteImage))
+			Expect(podSpec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(pvcNamePrefix + ghostName))
+			Expect(podSpec.Volumes).To(ContainElement(HaveField("Name", "target")))
+
+			By("completing the Job")
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: job.Name + "-pod", Namespace: "default", Labels: map[string]string{jobNameLabel: job.Name}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: backupUploadContainer, Image: backupToolsImage}}},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status.Phase = corev1.PodSucceeded
+			pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+				Name: backupUploadContainer,
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Message: `{"size":1024,"checksum":"` + checksum + `"}`,
+				}},
+			}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+			now := metav1.Now()
+			job.Status.StartTime = &now
+			job.Status.CompletionTime = &now
+			job.Status.Succeeded = 1
+			job.Status.Conditions = []batchv1.JobCondition{
+				{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue, LastTransitionTime: now},
+				{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: now},
+			}
+			Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			Expect(backup.Status.Phase).To(Equal(blogv2.GhostBackupPhaseSucceeded))
+			Expect(backup.Status.Size).To(Equal(int64(1024)))
+			Expect(backup.Status.Checksum).To(Equal(checksum))
+			Expect(backup.Status.CompletionTime).NotTo(BeNil())
+			Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+		})
+	})
+
+	Context("When the status of a started backup was not recorded", func() {
+		const ghostName = "unrecorded-blog"
+		const resourceName = "unrecorded-blog-manual"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			ghost := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: ghostName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, ghost)).To(Succeed())
+			backup := &blogv2.GhostBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostBackupSpec{
+					GhostName: ghostName,
+					Target: blogv2.BackupTarget{
+						PVC: &blogv2.PVCBackupTarget{ClaimName: "backups", Path: "blogs"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, backup)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			backup := &blogv2.GhostBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, backup)).To(Succeed())
+			deleteGhost(ctx, types.NamespacedName{Name: ghostName, Namespace: "default"})
+		})
+
+		It("should record the Job and the archive location from the existing Job", func() {
+			controllerReconciler := &GhostBackupReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("losing the status written after creating the Job")
+			backup := &blogv2.GhostBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			backup.Status = blogv2.GhostBackupStatus{}
+			Expect(k8sClient.Status().Update(ctx, backup)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			Expect(backup.Status.Phase).To(Equal(blogv2.GhostBackupPhaseRunning))
+			Expect(backup.Status.JobName).To(Equal(boundedName(backupJobNamePrefix, resourceName)))
+			Expect(backup.Status.Location).To(Equal("pvc://backups/blogs/default/" + ghostName + "/" + resourceName + ".tar.gz"))
+			Expect(backup.Status.StartTime).NotTo(BeNil())
+		})
+	})
+
+	Context("When the Ghost pods hold a ReadWriteOnce volume", func() {
+		const ghostName = "rwo-blog"
+		const resourceName = "rwo-blog-manual"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+
+		BeforeEach(func() {
+			ghost := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: ghostName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, ghost)).To(Succeed())
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: pvcNamePrefix + ghostName, Namespace: "default"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+					Resources: corev1.VolumeResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: ghostName + "-pod", Namespace: "default", Labels: selectorForGhost(ghost)},
+				Spec: corev1.PodSpec{
+					NodeName:   "node-1",
+					Containers: []corev1.Container{{Name: "ghost", Image: "ghost:alpine"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			backup := &blogv2.GhostBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostBackupSpec{
+					GhostName: ghostName,
+					Target: blogv2.BackupTarget{
+						PVC: &blogv2.PVCBackupTarget{ClaimName: "backups", Path: "blogs"},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, backup)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			backup := &blogv2.GhostBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, backup)).To(Succeed())
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: ghostName + "-pod", Namespace: "default"}}
+			Expect(k8sClient.Delete(ctx, pod)).To(Succeed())
+			pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: pvcNamePrefix + ghostName, Namespace: "default"}}
+			Expect(k8sClient.Delete(ctx, pvc)).To(Succeed())
+			deleteGhost(ctx, types.NamespacedName{Name: ghostName, Namespace: "default"})
+		})
+
+		It("should require the backup Job to run on the node of the Ghost pods", func() {
+			controllerReconciler := &GhostBackupReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			backup := &blogv2.GhostBackup{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, backup)).To(Succeed())
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backup.Status.JobName, Namespace: "default"}, job)).To(Succeed())
+			affinity := job.Spec.Template.Spec.Affinity
+			Expect(affinity).NotTo(BeNil())
+			Expect(affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(ConsistOf(
+				HaveField("TopologyKey", corev1.LabelHostname),
+			))
+		})
+	})
+})
@@ -0,0 +1,313 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	blogv2 "example.com/api/v2"
+)
+
+// restorePollInterval is how often a restore checks on the Ghost pods, which it does not watch
+const restorePollInterval = 5 * time.Second
+
+// GhostRestoreReconciler reconciles a GhostRestore object
+type GhostRestoreReconciler struct {
+	client.Client
+	Scheme  *runtime.Scheme
+	recoder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=blog.example.com,resources=ghostrestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=blog.example.com,resources=ghostrestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=blog.example.com,resources=ghostrestores/finalizers,verbs=update
+// +kubebuilder:rbac:groups=blog.example.com,resources=ghostbackups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+// Reconcile walks a GhostRestore through its phases: the Ghost is scaled
+// down, a Job replaces its content and database with the archive, a second
+// Job migrates the database to the image of the Ghost, and the Ghost is
+// scaled back up. The Ghost controller holds the Deployment at zero replicas
+// while the restore is in ScalingDown, Restoring or Migrating.
+func (r *GhostRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := log.FromContext(ctx)
+
+	restore := &blogv2.GhostRestore{}
+	if err := r.Get(ctx, req.NamespacedName, restore); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if restoreFinished(restore) {
+		return ctrl.Result{}, nil
+	}
+
+	ghost := &blogv2.Ghost{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: restore.Namespace, Name: restore.Spec.GhostName}, ghost)
+	if errors.IsNotFound(err) {
+		if restoreInProgress(restore) {
+			return ctrl.Result{}, r.failRestore(ctx, restore, fmt.Sprintf("Ghost %s was deleted", restore.Spec.GhostName))
+		}
+		// The Ghost may be created along with the restore, in a fresh namespace
+		return ctrl.Result{RequeueAfter: restorePollInterval}, r.waitForRestore(ctx, restore, fmt.Sprintf("Waiting for Ghost %s", restore.Spec.GhostName))
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	switch restore.Status.Phase {
+	case blogv2.GhostRestorePhaseScalingDown:
+		return r.restoreWhenScaledDown(ctx, restore, ghost)
+	case blogv2.GhostRestorePhaseRestoring:
+		return ctrl.Result{}, r.migrateWhenRestored(ctx, restore, ghost)
+	case blogv2.GhostRestorePhaseMigrating:
+		return ctrl.Result{}, r.scaleUpWhenMigrated(ctx, restore)
+	case blogv2.GhostRestorePhaseScalingUp:
+		return r.succeedWhenAvailable(ctx, restore, ghost)
+	}
+
+	// Pending, check the archive and that no other restore runs on the Ghost
+	archive, message, err := r.resolveArchive(ctx, restore)
+	if err != nil || message != "" {
+		return ctrl.Result{RequeueAfter: restorePollInterval}, err
+	}
+	active, err := activeRestore(ctx, r.Client, ghost)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if active != nil {
+		return ctrl.Result{RequeueAfter: restorePollInterval}, r.waitForRestore(ctx, restore, "Waiting for GhostRestore "+active.Name+" to finish")
+	}
+
+	now := metav1.Now()
+	restore.Status.Phase = blogv2.GhostRestorePhaseScalingDown
+	restore.Status.Message = "Waiting for the Ghost pods to stop"
+	restore.Status.Location = archiveLocation(archive.target, archive.key)
+	restore.Status.StartTime = &now
+	r.recoder.Event(restore, corev1.EventTypeNormal, "ScalingDown", "Scaling Ghost "+ghost.Name+" down to restore "+restore.Status.Location)
+	log.Info("Restore started", "restore", restore.Name, "ghost", ghost.Name, "location", restore.Status.Location)
+	return ctrl.Result{RequeueAfter: restorePollInterval}, r.Status().Update(ctx, restore)
+}
+
+// resolveArchive returns the archive of the restore. The message explains
+// what the restore waits for when the archive is not there yet, and a
+// restore whose backup failed is failed along with it.
+func (r *GhostRestoreReconciler) resolveArchive(ctx context.Context, restore *blogv2.GhostRestore) (restoreArchive, string, error) {
+	source := restore.Spec.Source
+	if source.Archive != nil {
+		return archiveFromURL(source.Archive), "", nil
+	}
+
+	backup := &blogv2.GhostBackup{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: restore.Namespace, Name: source.BackupName}, backup)
+	if errors.IsNotFound(err) {
+		message := fmt.Sprintf("GhostBackup %s not found", source.BackupName)
+		return restoreArchive{}, message, r.failRestore(ctx, restore, message)
+	}
+	if err != nil {
+		return restoreArchive{}, "", err
+	}
+	switch backup.Status.Phase {
+	case blogv2.GhostBackupPhaseSucceeded:
+		return archiveFromBackup(backup), "", nil
+	case blogv2.GhostBackupPhaseFailed:
+		message := fmt.Sprintf("GhostBackup %s failed", backup.Name)
+		return restoreArchive{}, message, r.failRestore(ctx, restore, message)
+	}
+	message := fmt.Sprintf("Waiting for GhostBackup %s to succeed", backup.Name)
+	return restoreArchive{}, message, r.waitForRestore(ctx, restore, message)
+}
+
+// restoreWhenScaledDown starts the restore Job once the Ghost controller
+// holds the Ghost scaled down and its pods are gone.
+func (r *GhostRestoreReconciler) restoreWhenScaledDown(ctx context.Context, restore *blogv2.GhostRestore, ghost *blogv2.Ghost) (ctrl.Result, error) {
+	if !meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionRestoring) {
+		return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+	}
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(ghost.Namespace), client.MatchingLabels(selectorForGhost(ghost))); err != nil {
+		return ctrl.Result{}, err
+	}
+	if len(pods.Items) > 0 {
+		return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+	}
+
+	archive, message, err := r.resolveArchive(ctx, restore)
+	if err != nil || message != "" {
+		return ctrl.Result{RequeueAfter: restorePollInterval}, err
+	}
+	claimName, err := lookupDataClaimName(ctx, r.Client, ghost)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	job := generateRestoreJob(restore, ghost, claimName, archive)
+	if err := r.createJob(ctx, restore, job); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recoder.Event(restore, corev1.EventTypeNormal, "Restoring", "Restore Job "+job.Name+" created")
+	log.FromContext(ctx).Info("Restore Job created", "restore", restore.Name, "job", job.Name)
+
+	restore.Status.Phase = blogv2.GhostRestorePhaseRestoring
+	restore.Status.Message = "Restore Job " + job.Name + " is running"
+	restore.Status.JobName = job.Name
+	return ctrl.Result{}, r.Status().Update(ctx, restore)
+}
+
+// migrateWhenRestored starts the migration Job once the restore Job completed
+func (r *GhostRestoreReconciler) migrateWhenRestored(ctx context.Context, restore *blogv2.GhostRestore, ghost *blogv2.Ghost) error {
+	done, err := r.jobDone(ctx, restore, restore.Status.JobName)
+	if err != nil || !done {
+		return err
+	}
+
+	claimName, err := lookupDataClaimName(ctx, r.Client, ghost)
+	if err != nil {
+		return err
+	}
+	job, err := generateMigrationJob(ghost, claimName, boundedName(migrationJobNamePrefix, restore.Name))
+	if err != nil {
+		return err
+	}
+	if err := r.createJob(ctx, restore, job); err != nil {
+		return err
+	}
+	r.recoder.Event(restore, corev1.EventTypeNormal, "Migrating", "Migration Job "+job.Name+" created")
+	log.FromContext(ctx).Info("Migration Job created", "restore", restore.Name, "job", job.Name)
+
+	restore.Status.Phase = blogv2.GhostRestorePhaseMigrating
+	restore.Status.Message = "Migration Job " + job.Name + " is running"
+	restore.Status.MigrationJobName = job.Name
+	return r.Status().Update(ctx, restore)
+}
+
+// scaleUpWhenMigrated releases the Ghost once the migration Job completed
+func (r *GhostRestoreReconciler) scaleUpWhenMigrated(ctx context.Context, restore *blogv2.GhostRestore) error {
+	done, err := r.jobDone(ctx, restore, restore.Status.MigrationJobName)
+	if err != nil || !done {
+		return err
+	}
+	restore.Status.Phase = blogv2.GhostRestorePhaseScalingUp
+	restore.Status.Message = "Waiting for the Ghost pods to become available"
+	r.recoder.Event(restore, corev1.EventTypeNormal, "ScalingUp", "Scaling Ghost "+restore.Spec.GhostName+" back up")
+	return r.Status().Update(ctx, restore)
+}
+
+// succeedWhenAvailable completes the restore once the Ghost serves again
+func (r *GhostRestoreReconciler) succeedWhenAvailable(ctx context.Context, restore *blogv2.GhostRestore, ghost *blogv2.Ghost) (ctrl.Result, error) {
+	deploymentName := ghost.Status.DeploymentName
+	if deploymentName == "" {
+		deploymentName = deploymentNamePrefix + ghost.Name
+	}
+	deployment := &appsv1.Deployment{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: ghost.Namespace, Name: deploymentName}, deployment); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas == 0 || deployment.Status.AvailableReplicas < *deployment.Spec.Replicas {
+		return ctrl.Result{RequeueAfter: restorePollInterval}, nil
+	}
+
+	restore.Status.Phase = blogv2.GhostRestorePhaseSucceeded
+	restore.Status.Message = "Ghost " + ghost.Name + " serves " + restore.Status.Location
+	now := metav1.Now()
+	restore.Status.CompletionTime = &now
+	r.recoder.Event(restore, corev1.EventTypeNormal, "RestoreSucceeded", restore.Status.Message)
+	log.FromContext(ctx).Info("Restore succeeded", "restore", restore.Name, "ghost", ghost.Name)
+	return ctrl.Result{}, r.Status().Update(ctx, restore)
+}
+
+// jobDone reports whether the Job of the restore completed, and fails the
+// restore when the Job failed. The Ghost scales back up either way.
+func (r *GhostRestoreReconciler) jobDone(ctx context.Context, restore *blogv2.GhostRestore, jobName string) (bool, error) {
+	job := &batchv1.Job{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: restore.Namespace, Name: jobName}, job)
+	if errors.IsNotFound(err) {
+		return false, r.failRestore(ctx, restore, "Job "+jobName+" was deleted")
+	}
+	if err != nil {
+		return false, err
+	}
+	switch {
+	case jobHasCondition(job, batchv1.JobComplete):
+		return true, nil
+	case jobHasCondition(job, batchv1.JobFailed):
+		return false, r.failRestore(ctx, restore, "Job "+jobName+" failed: "+jobConditionMessage(job, batchv1.JobFailed))
+	}
+	return false, nil
+}
+
+// createJob creates a Job owned by the restore, a Job left by an earlier
+// attempt is taken over.
+func (r *GhostRestoreReconciler) createJob(ctx context.Context, restore *blogv2.GhostRestore, job *batchv1.Job) error {
+	if err := controllerutil.SetControllerReference(restore, job, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, job); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// waitForRestore keeps a restore pending with a message explaining why
+func (r *GhostRestoreReconciler) waitForRestore(ctx context.Context, restore *blogv2.GhostRestore, message string) error {
+	if restore.Status.Phase == blogv2.GhostRestorePhasePending && restore.Status.Message == message {
+		return nil
+	}
+	restore.Status.Phase = blogv2.GhostRestorePhasePending
+	restore.Status.Message = message
+	return r.Status().Update(ctx, restore)
+}
+
+// failRestore marks a restore that cannot be completed as failed
+func (r *GhostRestoreReconciler) failRestore(ctx context.Context, restore *blogv2.GhostRestore, message string) error {
+	now := metav1.Now()
+	restore.Status.Phase = blogv2.GhostRestorePhaseFailed
+	restore.Status.Message = message
+	restore.Status.CompletionTime = &now
+	r.recoder.Event(restore, corev1.EventTypeWarning, "RestoreFailed", message)
+	log.FromContext(ctx).Info("Restore failed", "restore", restore.Name, "message", message)
+	return r.Status().Update(ctx, restore)
+}
+
+// restoreFinished reports whether the restore succeeded or failed
+func restoreFinished(restore *blogv2.GhostRestore) bool {
+	return restore.Status.Phase == blogv2.GhostRestorePhaseSucceeded || restore.Status.Phase == blogv2.GhostRestorePhaseFailed
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *GhostRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recoder = mgr.GetEventRecorderFor("ghostrestore-controller")
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&blogv2.GhostRestore{}).
+		Owns(&batchv1.Job{}).
+		Complete(r)
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	blogv2 "example.com/api/v2"
+)
+
+var _ = Describe("GhostRestore Controller", func() {
+	Context("When restoring a backup into a Ghost", func() {
+		const ghostName = "restored-blog"
+		const backupName = "restored-blog-nightly"
+		const resourceName = "restored-blog-drill"
+		const checksum = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+		ctx := context.Background()
+		typeNamespacedName := types.NamespacedName{Name: resourceName, Namespace: "default"}
+		ghostNamespacedName := types.NamespacedName{Name: ghostName, Namespace: "default"}
+
+		BeforeEach(func() {
+			ghost := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: ghostName, Namespace: "default"},
+				Spec:       blogv2.GhostSpec{Image: blogv2.GhostImageSpec{Tag: "alpine"}},
+			}
+			Expect(k8sClient.Create(ctx, ghost)).To(Succeed())
+			backup := &blogv2.GhostBackup{
+				ObjectMeta: metav1.ObjectMeta{Name: backupName, Namespace: "default"},
+				Spec: blogv2.GhostBackupSpec{
+					GhostName: ghostName,
+					Target:    blogv2.BackupTarget{PVC: &blogv2.PVCBackupTarget{ClaimName: "backups"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, backup)).To(Succeed())
+			backup.Status.Phase = blogv2.GhostBackupPhaseSucceeded
+			backup.Status.Checksum = checksum
+			Expect(k8sClient.Status().Update(ctx, backup)).To(Succeed())
+			restore := &blogv2.GhostRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: resourceName, Namespace: "default"},
+				Spec: blogv2.GhostRestoreSpec{
+					GhostName: ghostName,
+					Source:    blogv2.RestoreSource{BackupName: backupName},
+				},
+			}
+			Expect(k8sClient.Create(ctx, restore)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			restore := &blogv2.GhostRestore{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, restore)).To(Succeed())
+			backup := &blogv2.GhostBackup{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: backupName, Namespace: "default"}, backup)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, backup)).To(Succeed())
+			deleteGhost(ctx, ghostNamespacedName)
+		})
+
+		It("should scale the Ghost down, restore and migrate it, and scale it back up", func() {
+			restoreReconciler := &GhostRestoreReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+			ghostReconciler := &GhostReconciler{
+				Client:  k8sClient,
+				Scheme:  k8sClient.Scheme(),
+				recoder: record.NewFakeRecorder(100),
+			}
+			completeJob := func(name string) {
+				job := &batchv1.Job{}
+				Expect(k8sClient.Get(ctx, types.NamespacedName{Name: name, Namespace: "default"}, job)).To(Succeed())
+				now := metav1.Now()
+				job.Status.StartTime = &now
+				job.Status.CompletionTime = &now
+				job.Status.Succeeded = 1
+				job.Status.Conditions = []batchv1.JobCondition{
+					{Type: batchv1.JobSuccessCriteriaMet, Status: corev1.ConditionTrue, LastTransitionTime: now},
+					{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: now},
+				}
+				Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+			}
+
+			_, err := restoreReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			restore := &blogv2.GhostRestore{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(blogv2.GhostRestorePhaseScalingDown))
+			Expect(restore.Status.Location).To(Equal("pvc://backups/default/" + ghostName + "/" + backupName + ".tar.gz"))
+
+			By("holding the Ghost scaled down")
+			_, err = ghostReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: ghostNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			ghost := &blogv2.Ghost{}
+			Expect(k8sClient.Get(ctx, ghostNamespacedName, ghost)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(ghost.Status.Conditions, conditionRestoring)).To(BeTrue())
+			deployment := &appsv1.Deployment{}
+			deploymentKey := types.NamespacedName{Name: deploymentNamePrefix + ghostName, Namespace: "default"}
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(0)))
+
+			By("restoring the archive")
+			_, err = restoreReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(blogv2.GhostRestorePhaseRestoring))
+			job := &batchv1.Job{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: restore.Status.JobName, Namespace: "default"}, job)).To(Succeed())
+			podSpec := job.Spec.Template.Spec
+			Expect(podSpec.InitContainers).To(HaveLen(2))
+			Expect(podSpec.InitContainers[0].Name).To(Equal("fetch"))
+			Expect(podSpec.InitContainers[1].Env).To(ContainElement(corev1.EnvVar{Name: "CHECKSUM", Value: checksum}))
+			Expect(podSpec.Containers[0].Name).To(Equal("load-database"))
+			Expect(podSpec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(pvcNamePrefix + ghostName))
+
+			By("migrating the restored database")
+			completeJob(restore.Status.JobName)
+			_, err = restoreReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(blogv2.GhostRestorePhaseMigrating))
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: restore.Status.MigrationJobName, Namespace: "default"}, job)).To(Succeed())
+			Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(ghost.Spec.ImageReference()))
+			Expect(job.Spec.Template.Spec.Containers[0].ReadinessProbe).To(BeNil())
+
+			By("scaling the Ghost back up")
+			completeJob(restore.Status.MigrationJobName)
+			_, err = restoreReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(blogv2.GhostRestorePhaseScalingUp))
+
+			_, err = ghostReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: ghostNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, ghostNamespacedName, ghost)).To(Succeed())
+			Expect(meta.FindStatusCondition(ghost.Status.Conditions, conditionRestoring)).To(BeNil())
+			Expect(k8sClient.Get(ctx, deploymentKey, deployment)).To(Succeed())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+
+			deployment.Status.Replicas = 1
+			deployment.Status.UpdatedReplicas = 1
+			deployment.Status.ReadyReplicas = 1
+			deployment.Status.AvailableReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, deployment)).To(Succeed())
+
+			_, err = restoreReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, typeNamespacedName, restore)).To(Succeed())
+			Expect(restore.Status.Phase).To(Equal(blogv2.GhostRestorePhaseSucceeded))
+			Expect(restore.Status.CompletionTime).NotTo(BeNil())
+		})
+	})
+})
@@ -0,0 +1,93 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ignoreStatusChanges drops update events that only touch the status or the
+// bookkeeping metadata of an object. Unlike GenerationChangedPredicate it also
+// works for kinds that do not track a generation, such as Services and PVCs.
+func ignoreStatusChanges() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(withoutStatus(e.ObjectOld), withoutStatus(e.ObjectNew))
+		},
+	}
+}
+
+// rolloutChanged lets spec changes and rollout progress of a Deployment or
+// StatefulSet through, so the Ghost status follows the pods.
+func rolloutChanged() predicate.Predicate {
+	return predicate.Or(ignoreStatusChanges(), predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			switch oldObj := e.ObjectOld.(type) {
+			case *appsv1.Deployment:
+				newObj, ok := e.ObjectNew.(*appsv1.Deployment)
+				return ok && deploymentProgressChanged(oldObj, newObj)
+			case *appsv1.StatefulSet:
+				newObj, ok := e.ObjectNew.(*appsv1.StatefulSet)
+				return ok && oldObj.Status.ReadyReplicas != newObj.Status.ReadyReplicas
+			}
+			return false
+		},
+	})
+}
+
+func deploymentProgressChanged(oldObj, newObj *appsv1.Deployment) bool {
+	oldStatus, newStatus := oldObj.Status, newObj.Status
+	if oldStatus.ObservedGeneration != newStatus.ObservedGeneration ||
+		oldStatus.Replicas != newStatus.Replicas ||
+		oldStatus.UpdatedReplicas != newStatus.UpdatedReplicas ||
+		oldStatus.ReadyReplicas != newStatus.ReadyReplicas ||
+		oldStatus.AvailableReplicas != newStatus.AvailableReplicas {
+		return true
+	}
+	for _, condType := range []appsv1.DeploymentConditionType{appsv1.DeploymentAvailable, appsv1.DeploymentProgressing} {
+		oldCond, newCond := deploymentCondition(oldObj, condType), deploymentCondition(newObj, condType)
+		if (oldCond == nil) != (newCond == nil) {
+			return true
+		}
+		if oldCond != nil && (oldCond.Status != newCond.Status || oldCond.Reason != newCond.Reason) {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutStatus returns the object as a map without its status and the
+// metadata the API server updates on every write.
+func withoutStatus(obj client.Object) map[string]interface{} {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		// Treat objects that cannot be converted as changed
+		return map[string]interface{}{"resourceVersion": obj.GetResourceVersion()}
+	}
+	delete(content, "status")
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		delete(metadata, "resourceVersion")
+		delete(metadata, "managedFields")
+	}
+	return content
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+var _ = Describe("Child predicates", func() {
+	It("should ignore status only updates of a Service", func() {
+		oldService := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "svc", ResourceVersion: "1"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		}
+		newService := oldService.DeepCopy()
+		newService.ResourceVersion = "2"
+		newService.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "10.0.0.1"}}
+		Expect(ignoreStatusChanges().Update(event.UpdateEvent{ObjectOld: oldService, ObjectNew: newService})).To(BeFalse())
+
+		newService.Spec.Type = corev1.ServiceTypeClusterIP
+		Expect(ignoreStatusChanges().Update(event.UpdateEvent{ObjectOld: oldService, ObjectNew: newService})).To(BeTrue())
+	})
+
+	It("should let Deployment rollout progress through", func() {
+		oldDeployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deploy", ResourceVersion: "1"}}
+		newDeployment := oldDeployment.DeepCopy()
+		newDeployment.ResourceVersion = "2"
+		Expect(rolloutChanged().Update(event.UpdateEvent{ObjectOld: oldDeployment, ObjectNew: newDeployment})).To(BeFalse())
+
+		newDeployment.Status.AvailableReplicas = 1
+		Expect(rolloutChanged().Update(event.UpdateEvent{ObjectOld: oldDeployment, ObjectNew: newDeployment})).To(BeTrue())
+	})
+})
@@ -19,6 +19,8 @@ package controller
 import (
 	"context"
 	"fmt"
+	"go/build"
+	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -26,14 +28,16 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v8/apis/volumesnapshot/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
-	blogv1 "example.com/api/v1"
+	blogv2 "example.com/api/v2"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -59,7 +63,15 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths:     []string{filepath.Join("..", "..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "config", "crd", "bases"),
+			// VolumeSnapshots taken by the Snapshot retention policy
+			filepath.Join(moduleCache(), "github.com", "kubernetes-csi", "external-snapshotter", "client", "v8@v8.0.0",
+				"config", "crd", "snapshot.storage.k8s.io_volumesnapshots.yaml"),
+			// HTTPRoutes exposing the Ghost through the Gateway API
+			filepath.Join(moduleCache(), "sigs.k8s.io", "gateway-api@v1.1.0",
+				"config", "crd", "standard", "gateway.networking.k8s.io_httproutes.yaml"),
+		},
 		ErrorIfCRDPathMissing: true,
 
 		// The BinaryAssetsDirectory is only required if you want to run the tests directly
@@ -77,7 +89,13 @@ var _ = BeforeSuite(func() {
 	Expect(err).NotTo(HaveOccurred())
 	Expect(cfg).NotTo(BeNil())
 
-	err = blogv1.AddToScheme(scheme.Scheme)
+	err = blogv2.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = gatewayv1.AddToScheme(scheme.Scheme)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = snapshotv1.AddToScheme(scheme.Scheme)
 	Expect(err).NotTo(HaveOccurred())
 
 	// +kubebuilder:scaffold:scheme
@@ -88,6 +106,14 @@ var _ = BeforeSuite(func() {
 
 })
 
+// moduleCache returns the directory the Go modules the tests depend on are downloaded to
+func moduleCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	return filepath.Join(build.Default.GOPATH, "pkg", "mod")
+}
+
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	cancel()
@@ -0,0 +1,291 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/distribution/reference"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	blogv2 "example.com/api/v2"
+)
+
+// log is for logging in this package.
+var ghostlog = logf.Log.WithName("ghost-resource")
+
+// SetupGhostWebhookWithManager registers the webhook for Ghost in the manager.
+func SetupGhostWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&blogv2.Ghost{}).
+		WithValidator(&GhostCustomValidator{Client: mgr.GetClient()}).
+		WithDefaulter(&GhostCustomDefaulter{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-blog-example-com-v2-ghost,mutating=true,failurePolicy=fail,sideEffects=None,groups=blog.example.com,resources=ghosts,verbs=create;update,versions=v2,name=mghost-v2.kb.io,admissionReviewVersions=v1
+
+// GhostCustomDefaulter sets default values on the fields of a Ghost left
+// empty by the user, so the stored object shows what is actually deployed.
+type GhostCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &GhostCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind Ghost.
+func (d *GhostCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	ghost, ok := obj.(*blogv2.Ghost)
+	if !ok {
+		return fmt.Errorf("expected a Ghost object but got %T", obj)
+	}
+	ghostlog.Info("Defaulting for Ghost", "name", ghost.GetName())
+
+	spec := &ghost.Spec
+	if spec.Image.Repository == "" {
+		spec.Image.Repository = blogv2.DefaultImageRepository
+	}
+	if spec.Image.Tag == "" && spec.Image.Digest == "" {
+		spec.Image.Tag = blogv2.DefaultImageTag
+	}
+
+	// Replicas are left to the autoscaler when there is one
+	if spec.Replicas == nil && spec.Autoscaling == nil {
+		replicas := blogv2.DefaultReplicas
+		spec.Replicas = &replicas
+	}
+
+	// Service
+	spec.Service.Type = spec.ServiceType()
+	spec.Service.Port = spec.ServicePort()
+
+	// Database
+	if spec.Database.Client == "" {
+		spec.Database.Client = spec.DatabaseClient()
+	}
+
+	// Storage, a claim brought by the user is left as it is
+	if spec.Storage.ExistingClaim == "" {
+		size := spec.StorageSize()
+		spec.Storage.Size = &size
+		spec.Storage.AccessModes = spec.StorageAccessModes()
+	}
+	spec.Storage.RetentionPolicy = spec.RetentionPolicy()
+
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-blog-example-com-v2-ghost,mutating=false,failurePolicy=fail,sideEffects=None,groups=blog.example.com,resources=ghosts,verbs=create;update,versions=v2,name=vghost-v2.kb.io,admissionReviewVersions=v1
+
+// GhostCustomValidator rejects Ghosts whose changes cannot be rolled out, and
+// Ghosts that would collide with another one on a hostname or node port.
+type GhostCustomValidator struct {
+	Client client.Client
+}
+
+var _ webhook.CustomValidator = &GhostCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Ghost.
+func (v *GhostCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	ghost, ok := obj.(*blogv2.Ghost)
+	if !ok {
+		return nil, fmt.Errorf("expected a Ghost object but got %T", obj)
+	}
+	ghostlog.Info("Validation for Ghost upon creation", "name", ghost.GetName())
+
+	allErrs := validateImage(ghost)
+	allErrs = append(allErrs, validateBackupSchedule(ghost)...)
+	collisions, err := v.validateCollisions(ctx, ghost)
+	if err != nil {
+		return nil, err
+	}
+	allErrs = append(allErrs, collisions...)
+	return nil, invalid(ghost, allErrs)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Ghost.
+func (v *GhostCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	ghost, ok := newObj.(*blogv2.Ghost)
+	if !ok {
+		return nil, fmt.Errorf("expected a Ghost object for the newObj but got %T", newObj)
+	}
+	oldGhost, ok := oldObj.(*blogv2.Ghost)
+	if !ok {
+		return nil, fmt.Errorf("expected a Ghost object for the oldObj but got %T", oldObj)
+	}
+	ghostlog.Info("Validation for Ghost upon update", "name", ghost.GetName())
+
+	// Let a Ghost being deleted drop its finalizer whatever its spec says
+	if !ghost.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	allErrs := validateImage(ghost)
+	allErrs = append(allErrs, validateBackupSchedule(ghost)...)
+	allErrs = append(allErrs, validateImmutableFields(oldGhost, ghost)...)
+	collisions, err := v.validateCollisions(ctx, ghost)
+	if err != nil {
+		return nil, err
+	}
+	allErrs = append(allErrs, collisions...)
+	return nil, invalid(ghost, allErrs)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Ghost.
+func (v *GhostCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateImage rejects an image the container runtime could not pull: the
+// repository, tag and digest have to make up a valid reference together.
+func validateImage(ghost *blogv2.Ghost) field.ErrorList {
+	var allErrs field.ErrorList
+	imagePath := field.NewPath("spec", "image")
+	image := ghost.Spec.Image
+
+	ref := ghost.Spec.ImageReference()
+	if _, err := reference.ParseNormalizedNamed(ref); err != nil {
+		allErrs = append(allErrs, field.Invalid(imagePath, ref, err.Error()))
+	}
+
+	for i, secret := range image.ImagePullSecrets {
+		if secret.Name == "" {
+			allErrs = append(allErrs, field.Required(imagePath.Child("imagePullSecrets").Index(i).Child("name"), "name of the pull Secret"))
+		}
+	}
+	return allErrs
+}
+
+// validateBackupSchedule rejects a backup schedule that is not a cron expression
+func validateBackupSchedule(ghost *blogv2.Ghost) field.ErrorList {
+	if ghost.Spec.Backup == nil {
+		return nil
+	}
+	if _, err := cron.ParseStandard(ghost.Spec.Backup.Schedule); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("spec", "backup", "schedule"), ghost.Spec.Backup.Schedule, err.Error())}
+	}
+	return nil
+}
+
+// validateImmutableFields rejects changes the children of the Ghost cannot
+// follow: the spec of a bound PVC is immutable, apart from growing it, and
+// switching the database client would start Ghost on an empty database.
+func validateImmutableFields(oldGhost, ghost *blogv2.Ghost) field.ErrorList {
+	var allErrs field.ErrorList
+	storagePath := field.NewPath("spec", "storage")
+	oldStorage, storage := oldGhost.Spec.Storage, ghost.Spec.Storage
+
+	if !equality.Semantic.DeepEqual(oldStorage.StorageClassName, storage.StorageClassName) {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("storageClassName"), "field is immutable"))
+	}
+	if oldStorage.ExistingClaim != storage.ExistingClaim {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("existingClaim"), "field is immutable"))
+	}
+	if len(oldStorage.AccessModes) > 0 && !equality.Semantic.DeepEqual(oldStorage.AccessModes, storage.AccessModes) {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("accessModes"), "field is immutable"))
+	}
+	if !equality.Semantic.DeepEqual(oldStorage.Selector, storage.Selector) {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("selector"), "field is immutable"))
+	}
+
+	oldSize, size := oldGhost.Spec.StorageSize(), ghost.Spec.StorageSize()
+	if size.Cmp(oldSize) < 0 {
+		allErrs = append(allErrs, field.Invalid(storagePath.Child("size"), size.String(),
+			fmt.Sprintf("storage cannot be shrunk below %s", oldSize.String())))
+	}
+
+	if oldGhost.Spec.DatabaseClient() != ghost.Spec.DatabaseClient() {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "database", "client"), "field is immutable"))
+	}
+	return allErrs
+}
+
+// validateCollisions rejects a Ghost serving a hostname or asking for a node
+// port another Ghost in the cluster already uses.
+func (v *GhostCustomValidator) validateCollisions(ctx context.Context, ghost *blogv2.Ghost) (field.ErrorList, error) {
+	ghosts := &blogv2.GhostList{}
+	if err := v.Client.List(ctx, ghosts); err != nil {
+		return nil, err
+	}
+
+	var allErrs field.ErrorList
+	for i := range ghosts.Items {
+		other := &ghosts.Items[i]
+		if other.Namespace == ghost.Namespace && other.Name == ghost.Name {
+			continue
+		}
+		otherHosts := hostnames(other)
+		for _, f := range hostnameFields(ghost) {
+			if otherHosts[f.host] {
+				allErrs = append(allErrs, field.Duplicate(f.path,
+					fmt.Sprintf("%s is already served by Ghost %s/%s", f.host, other.Namespace, other.Name)))
+			}
+		}
+		if nodePort := ghost.Spec.ServiceNodePort(); nodePort != 0 && (nodePort == other.Spec.ServiceNodePort() || nodePort == other.Status.NodePort) {
+			allErrs = append(allErrs, field.Duplicate(field.NewPath("spec", "service", "nodePort"),
+				fmt.Sprintf("%d is already used by Ghost %s/%s", nodePort, other.Namespace, other.Name)))
+		}
+	}
+	return allErrs, nil
+}
+
+// hostnameField is a hostname of the Ghost along with the field it is set in
+type hostnameField struct {
+	path *field.Path
+	host string
+}
+
+// hostnameFields returns the hostnames the Ghost is served on through its Ingress and HTTPRoute
+func hostnameFields(ghost *blogv2.Ghost) []hostnameField {
+	var fields []hostnameField
+	if ghost.Spec.Ingress != nil {
+		for i, host := range ghost.Spec.Ingress.Hosts {
+			fields = append(fields, hostnameField{path: field.NewPath("spec", "ingress", "hosts").Index(i), host: host})
+		}
+	}
+	if ghost.Spec.HTTPRoute != nil {
+		for i, host := range ghost.Spec.HTTPRoute.Hostnames {
+			fields = append(fields, hostnameField{path: field.NewPath("spec", "httpRoute", "hostnames").Index(i), host: host})
+		}
+	}
+	return fields
+}
+
+// hostnames returns the set of hostnames the Ghost is served on
+func hostnames(ghost *blogv2.Ghost) map[string]bool {
+	hosts := map[string]bool{}
+	for _, f := range hostnameFields(ghost) {
+		hosts[f.host] = true
+	}
+	return hosts
+}
+
+// invalid wraps the errors found on the Ghost into an Invalid API error
+func invalid(ghost *blogv2.Ghost, allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: blogv2.GroupVersion.Group, Kind: "Ghost"}, ghost.Name, allErrs)
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	blogv1 "example.com/api/v1"
+	blogv2 "example.com/api/v2"
+	// TODO (user): Add any additional imports if needed
+)
+
+var _ = Describe("Ghost Webhook", func() {
+	var (
+		obj       *blogv2.Ghost
+		oldObj    *blogv2.Ghost
+		validator GhostCustomValidator
+		defaulter GhostCustomDefaulter
+	)
+
+	BeforeEach(func() {
+		obj = &blogv2.Ghost{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-blog", Namespace: "default"},
+		}
+		oldObj = &blogv2.Ghost{
+			ObjectMeta: metav1.ObjectMeta{Name: "webhook-blog", Namespace: "default"},
+		}
+		validator = GhostCustomValidator{Client: k8sClient}
+		Expect(validator).NotTo(BeNil(), "Expected validator to be initialized")
+		defaulter = GhostCustomDefaulter{}
+		Expect(defaulter).NotTo(BeNil(), "Expected defaulter to be initialized")
+	})
+
+	Context("When creating Ghost under Defaulting Webhook", func() {
+		It("Should apply defaults when a required field is empty", func() {
+			By("calling the Default method to apply defaults")
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+
+			By("checking that the default values are set")
+			Expect(obj.Spec.Image.Repository).To(Equal(blogv2.DefaultImageRepository))
+			Expect(obj.Spec.Image.Tag).To(Equal(blogv2.DefaultImageTag))
+			Expect(*obj.Spec.Replicas).To(Equal(int32(1)))
+			Expect(obj.Spec.Service.Type).To(Equal(corev1.ServiceTypeNodePort))
+			Expect(obj.Spec.Service.Port).To(Equal(int32(80)))
+			Expect(obj.Spec.Database.Client).To(Equal(blogv2.DatabaseClientSQLite))
+			Expect(obj.Spec.Storage.Size.String()).To(Equal("1Gi"))
+			Expect(obj.Spec.Storage.AccessModes).To(ConsistOf(corev1.ReadWriteOnce))
+			Expect(obj.Spec.Storage.RetentionPolicy).To(Equal(blogv2.RetentionPolicyDelete))
+		})
+
+		It("Should leave the storage of an existing claim alone", func() {
+			obj.Spec.Storage.ExistingClaim = "my-claim"
+			Expect(defaulter.Default(ctx, obj)).To(Succeed())
+			Expect(obj.Spec.Storage.Size).To(BeNil())
+			Expect(obj.Spec.Storage.AccessModes).To(BeEmpty())
+		})
+	})
+
+	Context("When creating or updating Ghost under Validating Webhook", func() {
+		It("Should deny an image reference that cannot be pulled", func() {
+			obj.Spec.Image = blogv2.GhostImageSpec{Repository: "Registry.Example.com/Ghost", Tag: "5.96.0"}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.image")))
+
+			obj.Spec.Image = blogv2.GhostImageSpec{
+				Repository: "registry.example.com:5000/ghost",
+				Tag:        "5.96.0",
+				Digest:     "sha256:" + strings.Repeat("a", 64),
+			}
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a backup schedule that is not a cron expression", func() {
+			obj.Spec.Backup = &blogv2.GhostBackupScheduleSpec{
+				Schedule: "every night",
+				Target:   blogv2.BackupTarget{PVC: &blogv2.PVCBackupTarget{ClaimName: "backups"}},
+			}
+			_, err := validator.ValidateCreate(ctx, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.backup.schedule")))
+
+			obj.Spec.Backup.Schedule = "0 3 * * *"
+			Expect(validator.ValidateCreate(ctx, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny changing the storage class", func() {
+			oldClass, newClass := "standard", "fast"
+			oldObj.Spec.Storage.StorageClassName = &oldClass
+			obj.Spec.Storage.StorageClassName = &newClass
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.storage.storageClassName")))
+		})
+
+		It("Should deny shrinking the storage", func() {
+			oldSize, newSize := resource.MustParse("2Gi"), resource.MustParse("1Gi")
+			oldObj.Spec.Storage.Size = &oldSize
+			obj.Spec.Storage.Size = &newSize
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.storage.size")))
+		})
+
+		It("Should deny switching the database client", func() {
+			obj.Spec.Database.Client = blogv2.DatabaseClientMySQL
+			_, err := validator.ValidateUpdate(ctx, oldObj, obj)
+			Expect(err).To(MatchError(ContainSubstring("spec.database.client")))
+		})
+
+		It("Should admit growing the storage", func() {
+			newSize := resource.MustParse("2Gi")
+			obj.Spec.Storage.Size = &newSize
+			Expect(validator.ValidateUpdate(ctx, oldObj, obj)).Error().NotTo(HaveOccurred())
+		})
+
+		It("Should deny a Ghost colliding with another one", func() {
+			other := &blogv2.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: "other-blog", Namespace: "default"},
+				Spec: blogv2.GhostSpec{
+					Service: blogv2.GhostServiceSpec{NodePort: 30080},
+					Ingress: &blogv2.GhostIngressSpec{Hosts: []string{"blog.example.com"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, other)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, other)).To(Succeed())
+			}()
+
+			By("asking for the same hostname")
+			obj.Spec.HTTPRoute = &blogv2.GhostHTTPRouteSpec{
+				ParentRefs: []blogv2.GhostParentRef{{Name: "gateway"}},
+				Hostnames:  []string{"blog.example.com"},
+			}
+			Expect(k8sClient.Create(ctx, obj)).To(MatchError(ContainSubstring("spec.httpRoute.hostnames[0]")))
+
+			By("asking for the same node port")
+			obj.Spec.HTTPRoute = nil
+			obj.Spec.Service.NodePort = 30080
+			Expect(k8sClient.Create(ctx, obj)).To(MatchError(ContainSubstring("spec.service.nodePort")))
+		})
+	})
+
+	Context("When converting Ghost between versions", func() {
+		It("Should round trip a v2 Ghost through v1 without loss", func() {
+			size := resource.MustParse("5Gi")
+			obj.Spec = blogv2.GhostSpec{
+				Image:   blogv2.GhostImageSpec{Repository: "registry.example.com/ghost", Tag: "5.96.0"},
+				Storage: blogv2.GhostStorageSpec{Size: &size, RetentionPolicy: blogv2.RetentionPolicySnapshot},
+				Service: blogv2.GhostServiceSpec{Type: corev1.ServiceTypeClusterIP, Port: 8080},
+				Ingress: &blogv2.GhostIngressSpec{Hosts: []string{"blog.example.com"}},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				},
+			}
+			obj.Status.DeploymentName = "ghost-deployment-webhook-blog"
+
+			spoke := &blogv1.Ghost{}
+			Expect(spoke.ConvertFrom(obj)).To(Succeed())
+			Expect(spoke.Spec.ImageTag).To(Equal("5.96.0"))
+			Expect(spoke.Annotations).To(HaveKey(blogv1.V2GhostAnnotation))
+
+			hub := &blogv2.Ghost{}
+			Expect(spoke.ConvertTo(hub)).To(Succeed())
+			Expect(hub.Annotations).NotTo(HaveKey(blogv1.V2GhostAnnotation))
+			Expect(hub.Spec).To(Equal(obj.Spec))
+			Expect(hub.Status).To(Equal(obj.Status))
+		})
+
+		It("Should convert a plain v1 Ghost without annotations", func() {
+			spoke := &blogv1.Ghost{
+				ObjectMeta: metav1.ObjectMeta{Name: "webhook-blog", Namespace: "default"},
+				Spec:       blogv1.GhostSpec{ImageTag: "alpine"},
+			}
+			hub := &blogv2.Ghost{}
+			Expect(spoke.ConvertTo(hub)).To(Succeed())
+			Expect(hub.Spec.Image.Tag).To(Equal("alpine"))
+
+			back := &blogv1.Ghost{}
+			Expect(back.ConvertFrom(hub)).To(Succeed())
+			Expect(back.Annotations).To(BeEmpty())
+			Expect(back.Spec).To(Equal(spoke.Spec))
+		})
+	})
+})